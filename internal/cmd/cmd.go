@@ -27,6 +27,7 @@ func New() *cobra.Command {
 		Long:  desc + ".",
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
 			svc.SFTP.Disconnect()
+			svc.SSH.Disconnect()
 			if cmdutil.DebugMode {
 				out := cmd.OutOrStdout()
 				fmt.Fprintln(out)
@@ -37,7 +37,9 @@ func New() *cobra.Command {
 
 	cmd.AddCommand(newGetCmd())
 	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newPlexCmd())
 	cmd.AddCommand(newSetCmd())
+	cmd.AddCommand(newSSHCmd())
 
 	return cmd
 }
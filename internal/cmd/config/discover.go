@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
+)
+
+var (
+	plexDiscoverDesc = "Map media kinds to Plex library sections"
+)
+
+// Configuration entry holding the section of each library kind, in prompt order.
+var plexSectionKeys = []struct {
+	kind plex.LibraryKind
+	key  string
+}{
+	{plex.LibraryKindMovies, config.KeyPlexSectionsMovies},
+	{plex.LibraryKindTVShows, config.KeyPlexSectionsTVShows},
+	{plex.LibraryKindAnimes, config.KeyPlexSectionsAnimes},
+}
+
+const noSectionOption = "none"
+
+func newPlexDiscoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "discover",
+		Short: plexDiscoverDesc,
+		Long:  plexDiscoverDesc + ".",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return discoverPlexSections(cmd.Context(), cmd.OutOrStdout(), plex.NewServiceFromConfig(), prompt.NewInteractive())
+		},
+	}
+
+	return cmd
+}
+
+// Lists Plex library sections and asks which one holds each kind of media before saving the mapping.
+func discoverPlexSections(ctx context.Context, out io.Writer, plexService *plex.Service, p prompt.Prompter) error {
+	libraries, err := plexService.Libraries(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list Plex libraries: %w", err)
+	}
+	if len(libraries) == 0 {
+		return fmt.Errorf("no library found on Plex server")
+	}
+
+	for _, section := range plexSectionKeys {
+		candidates := lo.Filter(libraries, func(lib plex.Library, _ int) bool {
+			return lib.Type == section.kind.SectionType()
+		})
+
+		options := []string{noSectionOption}
+		defaultOption := noSectionOption
+		current := viper.GetString(section.key)
+		for _, lib := range candidates {
+			option := formatLibraryOption(lib)
+			options = append(options, option)
+			if current == strconv.Itoa(lib.ID) || current == lib.Name {
+				defaultOption = option
+			}
+		}
+		if current == "" {
+			if lib := plex.FindLibraryByName(candidates, section.kind); lib != nil {
+				defaultOption = formatLibraryOption(*lib)
+			}
+		}
+
+		selected, err := p.Select(fmt.Sprintf("Select Plex section for %s", section.kind), options, defaultOption)
+		if err != nil {
+			return err
+		}
+
+		value := ""
+		if selected != noSectionOption {
+			lib, _ := lo.Find(candidates, func(lib plex.Library) bool {
+				return formatLibraryOption(lib) == selected
+			})
+			value = strconv.Itoa(lib.ID)
+		}
+		viper.Set(section.key, value)
+	}
+
+	err = config.Save()
+	if err != nil {
+		return err
+	}
+
+	for _, section := range plexSectionKeys {
+		fmt.Fprintf(out, "%s=%s\n", section.key, viper.GetString(section.key))
+	}
+
+	return nil
+}
+
+func formatLibraryOption(lib plex.Library) string {
+	return fmt.Sprintf("%s (ID %d)", lib.Name, lib.ID)
+}
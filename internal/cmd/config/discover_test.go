@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
+)
+
+type selectPrompter struct {
+	selections map[string]string
+	options    map[string][]string
+	defaults   map[string]string
+}
+
+func (p *selectPrompter) Confirm(_ string, defaultValue bool) (bool, error) {
+	return defaultValue, nil
+}
+
+func (p *selectPrompter) Input(_, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
+
+func (p *selectPrompter) Password(label string) (string, error) {
+	return "", fmt.Errorf("unexpected prompt %q", label)
+}
+
+func (p *selectPrompter) Select(label string, options []string, defaultValue string) (string, error) {
+	p.options[label] = options
+	p.defaults[label] = defaultValue
+	if selection, ok := p.selections[label]; ok {
+		return selection, nil
+	}
+	return defaultValue, nil
+}
+
+func Test_Config_Plex_Discover(t *testing.T) {
+	tempDir := t.TempDir()
+	config.Dir = tempDir
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"MediaContainer":{"Directory":[
+			{"key":"1","title":"Films","type":"movie"},
+			{"key":"2","title":"Séries","type":"show"},
+			{"key":"3","title":"Animes","type":"show"}
+		]}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		for _, section := range plexSectionKeys {
+			viper.Set(section.key, "")
+		}
+	})
+
+	p := &selectPrompter{
+		selections: map[string]string{
+			"Select Plex section for movies":  "Films (ID 1)",
+			"Select Plex section for tvshows": "Séries (ID 2)",
+		},
+		options:  map[string][]string{},
+		defaults: map[string]string{},
+	}
+
+	output := new(bytes.Buffer)
+	err := discoverPlexSections(context.Background(), output, plex.NewService(server.URL, "token"), p)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"none", "Films (ID 1)"}, p.options["Select Plex section for movies"])
+	assert.Equal(t, []string{"none", "Séries (ID 2)", "Animes (ID 3)"}, p.options["Select Plex section for tvshows"])
+	assert.Equal(t, "none", p.defaults["Select Plex section for tvshows"])
+	assert.Equal(t, "Animes (ID 3)", p.defaults["Select Plex section for animes"])
+
+	assert.Equal(t, "1", viper.GetString(config.KeyPlexSectionsMovies))
+	assert.Equal(t, "2", viper.GetString(config.KeyPlexSectionsTVShows))
+	assert.Equal(t, "3", viper.GetString(config.KeyPlexSectionsAnimes))
+	assert.Equal(t, "plex.sections.movies=1\nplex.sections.tvshows=2\nplex.sections.animes=3\n", output.String())
+
+	content, err := os.ReadFile(filepath.Join(tempDir, config.Filename))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `tvshows: "2"`)
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
+)
+
+var (
+	plexLoginDesc = "Sign in to Plex to obtain an API token"
+	authURL       string
+
+	// Delay between two checks of the PIN state.
+	plexLoginPollInterval = 2 * time.Second
+)
+
+func newPlexLoginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: plexLoginDesc,
+		Long:  plexLoginDesc + ".",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return loginToPlex(cmd.Context(), cmd.OutOrStdout(), authURL)
+		},
+	}
+
+	cmd.Flags().StringVar(&authURL, "auth-url", plex.DefaultAuthURL, "Plex account service URL")
+	cmd.Flags().MarkHidden("auth-url")
+
+	return cmd
+}
+
+// Runs the Plex PIN flow: requests a PIN, waits for the user to claim it and saves the resulting token.
+func loginToPlex(ctx context.Context, out io.Writer, baseURL string) error {
+	clientID := viper.GetString(config.KeyPlexAPIClientID)
+	if clientID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return fmt.Errorf("could not generate client identifier: %w", err)
+		}
+		clientID = hex.EncodeToString(id)
+		viper.Set(config.KeyPlexAPIClientID, clientID)
+	}
+
+	plexService := plex.NewService(baseURL, "", plex.WithClientIdentifier(clientID))
+
+	pin, err := plexService.RequestPIN(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Open %s and enter the following code: %s\n", baseURL+"/link", pterm.Bold.Sprint(pin.Code))
+
+	spinner, err := pterm.DefaultSpinner.Start("Waiting for code to be claimed...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	token, err := plexService.WaitForToken(ctx, pin, plexLoginPollInterval)
+	if err != nil {
+		return err
+	}
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	viper.Set(config.KeyPlexAPIToken, token)
+	err = config.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Saved token to %s\n", config.KeyPlexAPIToken)
+
+	return nil
+}
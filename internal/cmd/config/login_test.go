@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+)
+
+func Test_Config_Plex_Login(t *testing.T) {
+	tempDir := t.TempDir()
+	config.Dir = tempDir
+	plexLoginPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		viper.Set(config.KeyPlexAPIClientID, "")
+		viper.Set(config.KeyPlexAPIToken, "")
+	})
+
+	checks := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("X-Plex-Client-Identifier"))
+		assert.Equal(t, config.AppName, r.Header.Get("X-Plex-Product"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/pins":
+			w.Write([]byte(`{"id":42,"code":"ABCD","authToken":null}`))
+
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/pins/42":
+			checks++
+			if checks < 3 {
+				w.Write([]byte(`{"id":42,"code":"ABCD","authToken":null}`))
+				return
+			}
+			w.Write([]byte(`{"id":42,"code":"ABCD","authToken":"secret-token"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	output := new(bytes.Buffer)
+	err := loginToPlex(context.Background(), output, server.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, checks)
+	assert.Contains(t, output.String(), server.URL+"/link")
+	assert.Contains(t, output.String(), "ABCD")
+	assert.Equal(t, "secret-token", viper.GetString(config.KeyPlexAPIToken))
+	assert.Len(t, viper.GetString(config.KeyPlexAPIClientID), 32)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, config.Filename))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "token: secret-token")
+}
+
+func Test_Config_Plex_Login_Expired_PIN(t *testing.T) {
+	config.Dir = t.TempDir()
+	plexLoginPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		viper.Set(config.KeyPlexAPIClientID, "")
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":42,"code":"ABCD","authToken":null,"expiresAt":"2000-01-01T00:00:00Z"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	err := loginToPlex(context.Background(), new(bytes.Buffer), server.URL)
+	assert.ErrorContains(t, err, "PIN ABCD expired before being claimed")
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	plexDesc = "Configure Plex integration"
+)
+
+func newPlexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plex",
+		Short: plexDesc,
+		Long:  plexDesc + ".",
+	}
+
+	cmd.AddCommand(newPlexDiscoverCmd())
+	cmd.AddCommand(newPlexLoginCmd())
+
+	return cmd
+}
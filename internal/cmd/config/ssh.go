@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	sshDesc = "Configure SSH connection"
+)
+
+func newSSHCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssh",
+		Short: sshDesc,
+		Long:  sshDesc + ".",
+	}
+
+	cmd.AddCommand(newSSHTrustCmd())
+
+	return cmd
+}
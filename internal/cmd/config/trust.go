@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+)
+
+var (
+	sshTrustDesc = "Trust host keys of the configured SSH server and jump hosts"
+	trustYes     bool
+)
+
+type hostKeyScanner interface {
+	ScanHostKeys(visit func(hk *svc.HostKey) error) error
+}
+
+func newSSHTrustCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trust",
+		Short: sshTrustDesc,
+		Long:  sshTrustDesc + ".",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var p prompt.Prompter
+			if trustYes {
+				p = prompt.NewAuto()
+			} else {
+				p = prompt.NewInteractive()
+			}
+			return trustHostKeys(cmd.OutOrStdout(), svc.SSH, p)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&trustYes, "yes", "y", false, "automatic yes to prompts")
+
+	return cmd
+}
+
+// Shows the host key of each hop up to the configured SSH server and appends unknown ones to the known hosts file once
+// confirmed. Changed host keys are never replaced.
+func trustHostKeys(out io.Writer, scanner hostKeyScanner, p prompt.Prompter) error {
+	return scanner.ScanHostKeys(func(hk *svc.HostKey) error {
+		switch hk.Status {
+		case svc.HostKeyKnown:
+			fmt.Fprintf(out, "%s %s key %s is already trusted\n", hk.Address, hk.Key.Type(), hk.Fingerprint())
+			return nil
+
+		case svc.HostKeyChanged:
+			fmt.Fprintf(out, "%s\n", pterm.Red(fmt.Sprintf("%s %s key has CHANGED, this may be a man-in-the-middle attack", hk.Address, hk.Key.Type())))
+			fmt.Fprintf(out, "  presented: %s\n", hk.Fingerprint())
+			for _, known := range hk.KnownKeys {
+				fmt.Fprintf(out, "  known:     %s (%s:%d)\n", ssh.FingerprintSHA256(known.Key), known.Filename, known.Line)
+			}
+			return fmt.Errorf("host key of %s changed, remove the outdated entry from known hosts if this is expected", hk.Address)
+		}
+
+		fmt.Fprintf(out, "%s presents %s key %s\n", hk.Address, hk.Key.Type(), hk.Fingerprint())
+		shouldTrust, err := p.Confirm(fmt.Sprintf("Trust host key of %s?", hk.Address), false)
+		if err != nil {
+			return err
+		}
+		if !shouldTrust {
+			return fmt.Errorf("host key of %s is not trusted", hk.Address)
+		}
+
+		if err := hk.Trust(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Added %s to known hosts\n", hk.Address)
+
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+type confirmPrompter struct {
+	confirmed bool
+	labels    []string
+}
+
+func (p *confirmPrompter) Confirm(label string, _ bool) (bool, error) {
+	p.labels = append(p.labels, label)
+	return p.confirmed, nil
+}
+
+func (p *confirmPrompter) Input(_, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
+
+func (p *confirmPrompter) Password(label string) (string, error) {
+	return "", fmt.Errorf("unexpected prompt %q", label)
+}
+
+func (p *confirmPrompter) Select(_ string, _ []string, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
+
+func Test_Config_SSH_Trust(t *testing.T) {
+	server := servicetest.NewServer(t)
+	hostKey := server.HostKey
+	host, port, err := net.SplitHostPort(server.Addr)
+	require.NoError(t, err)
+	knownHosts := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+	configtest.Set(t, map[string]any{config.KeySSHClientKnownHosts: knownHosts})
+	address := net.JoinHostPort(host, port)
+
+	t.Run("Declined", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		err := trustHostKeys(output, svc.SSH, &confirmPrompter{confirmed: false})
+
+		assert.EqualError(t, err, "host key of "+address+" is not trusted")
+		assert.Contains(t, output.String(), ssh.FingerprintSHA256(hostKey))
+		assert.NoFileExists(t, knownHosts)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		p := &confirmPrompter{confirmed: true}
+		err := trustHostKeys(output, svc.SSH, p)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Trust host key of " + address + "?"}, p.labels)
+		content, err := os.ReadFile(knownHosts)
+		require.NoError(t, err)
+		assert.Equal(t, "[127.0.0.1]:"+port+" "+strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey)))+"\n", string(content))
+	})
+
+	t.Run("Known", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		p := &confirmPrompter{confirmed: true}
+		err := trustHostKeys(output, svc.SSH, p)
+
+		require.NoError(t, err)
+		assert.Empty(t, p.labels)
+		assert.Contains(t, output.String(), "is already trusted")
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		other := servicetest.NewServer(t)
+		otherHost, otherPort, err := net.SplitHostPort(other.Addr)
+		require.NoError(t, err)
+		require.Equal(t, host, otherHost)
+
+		content, err := os.ReadFile(knownHosts)
+		require.NoError(t, err)
+		replaced := strings.ReplaceAll(string(content), ":"+port+" ", ":"+otherPort+" ")
+		require.NoError(t, os.WriteFile(knownHosts, []byte(replaced), 0o600))
+		configtest.Set(t, map[string]any{config.KeySSHClientKnownHosts: knownHosts})
+
+		output := new(bytes.Buffer)
+		p := &confirmPrompter{confirmed: true}
+		err = trustHostKeys(output, svc.SSH, p)
+
+		assert.ErrorContains(t, err, "host key of "+net.JoinHostPort(host, otherPort)+" changed")
+		assert.Empty(t, p.labels)
+		assert.Contains(t, output.String(), "CHANGED")
+		assert.Contains(t, output.String(), ssh.FingerprintSHA256(hostKey)+" ("+knownHosts+":1)")
+		after, _ := os.ReadFile(knownHosts)
+		assert.Equal(t, replaced, string(after))
+	})
+}
@@ -55,6 +55,14 @@ func (m *mockPrompter) Input(_, defaultValue string) (string, error) {
 	return r.value, r.err
 }
 
+func (m *mockPrompter) Password(label string) (string, error) {
+	return "", fmt.Errorf("unexpected prompt %q", label)
+}
+
+func (m *mockPrompter) Select(_ string, _ []string, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
+
 func Test_Movie_With_Dry_Run(t *testing.T) {
 	tempDir := t.TempDir()
 	config.Dir = tempDir
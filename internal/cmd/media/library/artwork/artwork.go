@@ -0,0 +1,91 @@
+package artwork
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+	"github.com/jeremiergz/nas-cli/internal/util/fsutil"
+)
+
+var (
+	artworkDesc = "Set Plex artwork of library items through the API"
+	yes         bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "artwork",
+		Aliases: []string{"art"},
+		Short:   artworkDesc,
+		Long:    artworkDesc + ".",
+		Args:    cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmdutil.DebugMode {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s PersistentPreRunE\n", cmd.CommandPath())
+			}
+
+			err := cmdutil.CallParentPersistentPreRunE(cmd.Parent(), args)
+			if err != nil {
+				return err
+			}
+
+			_, err = exec.LookPath(cmdutil.CommandExifTool)
+			if err != nil {
+				return fmt.Errorf("command not found: %s", cmdutil.CommandExifTool)
+			}
+
+			selectedDir := "."
+			if len(args) > 0 {
+				selectedDir = args[0]
+			}
+
+			return fsutil.InitializeWorkingDir(selectedDir)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			options := []string{
+				"movies",
+				"tvshows",
+				"animes",
+			}
+
+			selectedOption, _ := pterm.DefaultInteractiveSelect.
+				WithDefaultText("Select media type").
+				WithOptions(options).
+				Show()
+
+			var subCmd *cobra.Command
+			switch selectedOption {
+			case "movies":
+				subCmd = newMovieCmd()
+
+			case "tvshows":
+				subCmd = newTVShowCmd()
+
+			case "animes":
+				subCmd = newAnimeCmd()
+			}
+
+			fmt.Fprintln(out)
+
+			if err := subCmd.RunE(cmd, args); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "automatic yes to prompts")
+
+	cmd.AddCommand(newAnimeCmd())
+	cmd.AddCommand(newMovieCmd())
+	cmd.AddCommand(newTVShowCmd())
+
+	return cmd
+}
@@ -0,0 +1,272 @@
+package artwork
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/image"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	plexsvc "github.com/jeremiergz/nas-cli/internal/service/plex"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	animeDesc  = "Set artwork of animes"
+	movieDesc  = "Set artwork of movies"
+	tvShowDesc = "Set artwork of TV shows"
+)
+
+func newAnimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "animes",
+		Aliases: []string{"ani", "a"},
+		Short:   animeDesc,
+		Long:    animeDesc + ".",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), plexsvc.LibraryKindAnimes)
+		},
+	}
+	return cmd
+}
+
+func newMovieCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "movies",
+		Aliases: []string{"movie", "m"},
+		Short:   movieDesc,
+		Long:    movieDesc + ".",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), plexsvc.LibraryKindMovies)
+		},
+	}
+	return cmd
+}
+
+func newTVShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tvshows",
+		Aliases: []string{"tv", "t"},
+		Short:   tvShowDesc,
+		Long:    tvShowDesc + ".",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), plexsvc.LibraryKindTVShows)
+		},
+	}
+	return cmd
+}
+
+// Images to set on a library item, identified by the name of its folder.
+type target struct {
+	FolderName string
+	Item       *plex.Item
+	Images     []*image.Image
+}
+
+func process(ctx context.Context, out io.Writer, kind plexsvc.LibraryKind) error {
+	images, err := media.ListImages(config.WD)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		pterm.Success.Println("Nothing to process")
+		return nil
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start("Loading information...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	items, err := plex.IndexItemsByLocation(ctx, kind)
+	if err != nil {
+		return fmt.Errorf("could not fetch %s: %w", kind, err)
+	}
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	targets, unresolved, ambiguous := resolveTargets(images, items)
+	for _, folderName := range unresolved {
+		pterm.Warning.Printfln("Could not find %q in Plex library", folderName)
+	}
+	for _, folderName := range ambiguous {
+		pterm.Warning.Printfln("Found several Plex items stored in a %q folder, skipping", folderName)
+	}
+	if len(targets) == 0 {
+		pterm.Success.Println("Nothing to process")
+		return nil
+	}
+
+	printTargets(out, targets)
+
+	var p prompt.Prompter
+	if yes {
+		p = prompt.NewAuto()
+	} else {
+		p = prompt.NewInteractive()
+	}
+
+	if !yes {
+		fmt.Fprintln(out)
+		shouldProcess, err := p.Confirm("Process?", true)
+		if err != nil {
+			return nil
+		}
+		if !shouldProcess {
+			return nil
+		}
+	}
+
+	fmt.Fprintln(out)
+
+	spinner, err = pterm.DefaultSpinner.Start("Processing images...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	var imagesToConvert []*image.Image
+	for _, t := range targets {
+		imagesToConvert = append(imagesToConvert, t.Images...)
+	}
+	if err := image.ConvertToRequirements(imagesToConvert); err != nil {
+		return err
+	}
+
+	for _, t := range targets {
+		spinner.UpdateText(fmt.Sprintf("Uploading artwork of %s...", t.Item.Title))
+		if err := uploadTarget(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	pterm.Success.Printfln("Artwork set for %d item(s)", len(targets))
+
+	return nil
+}
+
+// Matches images with library items stored in folders named after their reference name. Returns targets sorted by
+// folder name along with the sorted names that could not be found in the library, and the ones matching several
+// distinct items, e.g. same-named folders in different pools.
+func resolveTargets(
+	images map[string][]*image.Image,
+	items map[string]*plex.Item,
+) (targets []*target, unresolved, ambiguous []string) {
+	itemsByFolderName := map[string][]*plex.Item{}
+	for folderPath, item := range items {
+		folderName := filepath.Base(folderPath)
+		if !slices.ContainsFunc(itemsByFolderName[folderName], func(i *plex.Item) bool { return i.RatingKey == item.RatingKey }) {
+			itemsByFolderName[folderName] = append(itemsByFolderName[folderName], item)
+		}
+	}
+
+	for folderName, folderImages := range images {
+		switch found := itemsByFolderName[folderName]; len(found) {
+		case 0:
+			unresolved = append(unresolved, folderName)
+
+		case 1:
+			slices.SortFunc(folderImages, func(a, b *image.Image) int {
+				return cmp.Compare(a.Name, b.Name)
+			})
+			targets = append(targets, &target{
+				FolderName: folderName,
+				Item:       found[0],
+				Images:     folderImages,
+			})
+
+		default:
+			ambiguous = append(ambiguous, folderName)
+		}
+	}
+
+	slices.SortFunc(targets, func(a, b *target) int {
+		return cmp.Compare(strings.ToLower(a.FolderName), strings.ToLower(b.FolderName))
+	})
+	slices.Sort(unresolved)
+	slices.Sort(ambiguous)
+
+	return targets, unresolved, ambiguous
+}
+
+func uploadTarget(ctx context.Context, t *target) error {
+	var seasonKeys map[int]string
+
+	for _, img := range t.Images {
+		ratingKey := t.Item.RatingKey
+
+		if seasonNumber, ok := media.ParseSeasonImageName(img.Name); ok {
+			if seasonKeys == nil {
+				_, seasons, err := plex.GetShowSeasons(ctx, t.Item.Title, t.Item.RatingKey)
+				if err != nil {
+					return err
+				}
+				seasonKeys = make(map[int]string, len(seasons))
+				for _, season := range seasons {
+					seasonKeys[season.Index] = season.RatingKey
+				}
+			}
+
+			ratingKey = seasonKeys[seasonNumber]
+			if ratingKey == "" {
+				pterm.Warning.Printfln("Could not find season %d of %q in Plex library", seasonNumber, t.Item.Title)
+				continue
+			}
+		}
+
+		if err := uploadImage(ctx, ratingKey, img); err != nil {
+			return fmt.Errorf("could not upload %s of %q: %w", img.Name, t.Item.Title, err)
+		}
+	}
+
+	return nil
+}
+
+func uploadImage(ctx context.Context, ratingKey string, img *image.Image) error {
+	file, err := os.Open(img.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	if img.Kind == image.KindBackground {
+		return plex.UploadBackground(ctx, ratingKey, file)
+	}
+	return plex.UploadPoster(ctx, ratingKey, file)
+}
+
+func printTargets(out io.Writer, targets []*target) {
+	lw := cmdutil.NewListWriter()
+
+	for _, t := range targets {
+		lw.AppendItem(fmt.Sprintf("%s %s", t.Item.Title, pterm.Gray("["+t.FolderName+"]")))
+		lw.Indent()
+		for _, img := range t.Images {
+			lw.AppendItem(img.Name)
+		}
+		lw.UnIndent()
+	}
+
+	fmt.Fprintln(out, lw.Render())
+}
@@ -0,0 +1,41 @@
+package artwork
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/image"
+)
+
+func Test_ResolveTargets(t *testing.T) {
+	images := map[string][]*image.Image{
+		"Show B": {
+			image.New("poster", "Show B.poster.jpg", image.KindPoster),
+			image.New("background", "Show B.bg.jpg", image.KindBackground),
+		},
+		"show a":  {image.New("poster", "show a.pt.jpg", image.KindPoster)},
+		"Unknown": {image.New("poster", "Unknown.poster.jpg", image.KindPoster)},
+		"Show C":  {image.New("poster", "Show C.poster.jpg", image.KindPoster)},
+	}
+	showB := &plex.Item{RatingKey: "2", Title: "Show B"}
+	items := map[string]*plex.Item{
+		"/volume1/tvshows/Show B": showB,
+		"/volume2/tvshows/Show B": showB,
+		"/volume1/tvshows/show a": {RatingKey: "1", Title: "Show A"},
+		"/volume1/tvshows/Show C": {RatingKey: "3", Title: "Show C"},
+		"/volume2/tvshows/Show C": {RatingKey: "4", Title: "Show C (2024)"},
+	}
+
+	targets, unresolved, ambiguous := resolveTargets(images, items)
+
+	require.Len(t, targets, 2)
+	assert.Equal(t, "show a", targets[0].FolderName)
+	assert.Equal(t, "1", targets[0].Item.RatingKey)
+	assert.Equal(t, "Show B", targets[1].FolderName)
+	assert.Equal(t, []string{"background", "poster"}, []string{targets[1].Images[0].Name, targets[1].Images[1].Name})
+	assert.Equal(t, []string{"Unknown"}, unresolved)
+	assert.Equal(t, []string{"Show C"}, ambiguous)
+}
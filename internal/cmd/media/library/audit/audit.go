@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	auditDesc = "Compare Plex episode counts with files found on the server"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Aliases: []string{"au"},
+		Short:   auditDesc,
+		Long:    auditDesc + ".",
+		Args:    cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmdutil.DebugMode {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s PersistentPreRunE\n", cmd.CommandPath())
+			}
+
+			err := cmdutil.CallParentPersistentPreRunE(cmd.Parent(), args)
+			if err != nil {
+				return err
+			}
+
+			err = svc.SFTP.Connect()
+			if err != nil {
+				return fmt.Errorf("failed to connect to SFTP server: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			options := []string{
+				"tvshows",
+				"animes",
+			}
+
+			selectedOption, _ := pterm.DefaultInteractiveSelect.
+				WithDefaultText("Select media type").
+				WithOptions(options).
+				Show()
+
+			var subCmd *cobra.Command
+			switch selectedOption {
+			case "tvshows":
+				subCmd = newTVShowCmd()
+
+			case "animes":
+				subCmd = newAnimeCmd()
+			}
+
+			fmt.Fprintln(out)
+
+			if err := subCmd.RunE(cmd, args); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newAnimeCmd())
+	cmd.AddCommand(newTVShowCmd())
+
+	return cmd
+}
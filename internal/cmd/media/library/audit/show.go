@@ -0,0 +1,269 @@
+package audit
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	animeDesc  = "Audit animes"
+	tvShowDesc = "Audit TV shows"
+)
+
+func newAnimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "animes",
+		Aliases: []string{"ani", "a"},
+		Short:   animeDesc,
+		Long:    animeDesc + ".",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return processShows(cmd.Context(), cmd.OutOrStdout(), plex.ShowsKindAnime)
+		},
+	}
+	return cmd
+}
+
+func newTVShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tvshows",
+		Aliases: []string{"tv", "t"},
+		Short:   tvShowDesc,
+		Long:    tvShowDesc + ".",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return processShows(cmd.Context(), cmd.OutOrStdout(), plex.ShowsKindTVShow)
+		},
+	}
+	return cmd
+}
+
+type showReport struct {
+	Name    string
+	Path    string
+	InPlex  bool
+	Seasons []*seasonReport
+}
+
+type seasonReport struct {
+	Index     int
+	PlexCount int
+	DiskCount int
+}
+
+func processShows(ctx context.Context, out io.Writer, kind plex.ShowsKind) error {
+	spinner, err := pterm.DefaultSpinner.Start("Loading information...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	plexShows, err := plex.FetchPlexShows(ctx, kind)
+	if err != nil {
+		return fmt.Errorf("could not fetch %ss: %w", kind.DisplayText(), err)
+	}
+
+	remoteShows, err := plex.GetShowsDetails(kind)
+	if err != nil {
+		return fmt.Errorf("could not list remote %s folders: %w", kind.DisplayText(), err)
+	}
+
+	eg, _ := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+	mu := sync.Mutex{}
+	reports := []*showReport{}
+	seenFolders := map[string]bool{}
+
+	for _, plexShow := range plexShows {
+		eg.Go(func() error {
+			folderName, plexSeasons, err := plex.GetShowSeasons(ctx, plexShow.Title, plexShow.RatingKey)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			seenFolders[folderName] = true
+			mu.Unlock()
+
+			remoteShowFolders := remoteShows[folderName]
+			if len(remoteShowFolders) == 0 {
+				// Plex library may point to folders that are not part of the configured destinations.
+				return nil
+			}
+
+			// Shows split across several destinations are audited as a whole.
+			diskCounts := map[int]int{}
+			for _, remoteShow := range remoteShowFolders {
+				counts, err := countEpisodes(remoteShow.Path)
+				if err != nil {
+					return err
+				}
+				for seasonIndex, count := range counts {
+					diskCounts[seasonIndex] += count
+				}
+			}
+
+			seasons := compareSeasons(plexSeasons, diskCounts)
+			if len(seasons) == 0 {
+				return nil
+			}
+
+			mu.Lock()
+			reports = append(reports, &showReport{
+				Name:    plexShow.Title,
+				Path:    joinPaths(remoteShowFolders),
+				InPlex:  true,
+				Seasons: seasons,
+			})
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return fmt.Errorf("could not audit %ss: %w", kind.DisplayText(), err)
+	}
+
+	for folderName, remoteShowFolders := range remoteShows {
+		if !seenFolders[folderName] {
+			reports = append(reports, &showReport{
+				Name: folderName,
+				Path: joinPaths(remoteShowFolders),
+			})
+		}
+	}
+
+	slices.SortFunc(reports, func(a, b *showReport) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	if len(reports) == 0 {
+		pterm.Success.Println("Nothing to report")
+		return nil
+	}
+
+	printReports(out, reports)
+
+	return nil
+}
+
+// Returns the number of episode files found in each season folder of given remote show.
+func countEpisodes(showPath string) (map[int]int, error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	showEntries, err := client.ReadDir(showPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read show directory %s: %w", showPath, err)
+	}
+
+	counts := map[int]int{}
+	for _, showEntry := range showEntries {
+		if !showEntry.IsDir() || !strings.HasPrefix(showEntry.Name(), "Season ") {
+			continue
+		}
+		seasonIndex, err := strconv.Atoi(strings.TrimPrefix(showEntry.Name(), "Season "))
+		if err != nil {
+			continue
+		}
+
+		seasonPath := filepath.Join(showPath, showEntry.Name())
+		seasonEntries, err := client.ReadDir(seasonPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read season directory %s: %w", seasonPath, err)
+		}
+		for _, seasonEntry := range seasonEntries {
+			extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(seasonEntry.Name())), ".")
+			if slices.Contains(util.AcceptedVideoExtensions, extension) {
+				counts[seasonIndex]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// Returns seasons whose episodes count differs between Plex and the disk, sorted by index.
+func compareSeasons(plexSeasons []*plex.Season, diskCounts map[int]int) []*seasonReport {
+	plexCounts := map[int]int{}
+	for _, season := range plexSeasons {
+		plexCounts[season.Index] = season.EpisodesCount
+	}
+
+	indexes := lo.Uniq(slices.Concat(lo.Keys(plexCounts), lo.Keys(diskCounts)))
+	slices.Sort(indexes)
+
+	seasons := []*seasonReport{}
+	for _, index := range indexes {
+		if plexCounts[index] != diskCounts[index] {
+			seasons = append(seasons, &seasonReport{
+				Index:     index,
+				PlexCount: plexCounts[index],
+				DiskCount: diskCounts[index],
+			})
+		}
+	}
+
+	return seasons
+}
+
+func joinPaths(remoteShowFolders []*plex.ShowDetails) string {
+	return strings.Join(lo.Map(remoteShowFolders, func(d *plex.ShowDetails, _ int) string { return d.Path }), ", ")
+}
+
+func printReports(out io.Writer, reports []*showReport) {
+	lw := cmdutil.NewListWriter()
+
+	for _, report := range reports {
+		if !report.InPlex {
+			lw.AppendItem(fmt.Sprintf("%s %s  %s",
+				report.Name,
+				pterm.Gray("["+report.Path+"]"),
+				pterm.Yellow("not picked up by Plex"),
+			))
+			continue
+		}
+
+		lw.AppendItem(fmt.Sprintf("%s %s", report.Name, pterm.Gray("["+report.Path+"]")))
+		lw.Indent()
+		for _, season := range report.Seasons {
+			var status string
+			if season.PlexCount > season.DiskCount {
+				status = pterm.Red(fmt.Sprintf("%d missing on disk", season.PlexCount-season.DiskCount))
+			} else {
+				status = pterm.Yellow(fmt.Sprintf("%d not picked up by Plex", season.DiskCount-season.PlexCount))
+			}
+			lw.AppendItem(fmt.Sprintf("Season %d: %s %s",
+				season.Index,
+				status,
+				pterm.Gray(fmt.Sprintf("(%d in Plex, %d on disk)", season.PlexCount, season.DiskCount)),
+			))
+		}
+		lw.UnIndent()
+	}
+
+	fmt.Fprintln(out, lw.Render())
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+)
+
+func Test_Compare_Seasons(t *testing.T) {
+	seasons := compareSeasons(
+		[]*plex.Season{
+			{Index: 1, EpisodesCount: 10},
+			{Index: 2, EpisodesCount: 8},
+			{Index: 3, EpisodesCount: 4},
+		},
+		map[int]int{1: 10, 2: 6, 3: 5, 4: 2},
+	)
+
+	assert.Equal(t, []*seasonReport{
+		{Index: 2, PlexCount: 8, DiskCount: 6},
+		{Index: 3, PlexCount: 4, DiskCount: 5},
+		{Index: 4, PlexCount: 0, DiskCount: 2},
+	}, seasons)
+}
+
+func Test_Compare_Seasons_Without_Differences(t *testing.T) {
+	seasons := compareSeasons(
+		[]*plex.Season{{Index: 1, EpisodesCount: 3}},
+		map[int]int{1: 3},
+	)
+
+	assert.Empty(t, seasons)
+}
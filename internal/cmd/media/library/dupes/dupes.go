@@ -0,0 +1,393 @@
+package dupes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	dupesDesc  = "Find media stored several times across destinations"
+	animeDesc  = "Find duplicate animes"
+	movieDesc  = "Find duplicate movies"
+	tvShowDesc = "Find duplicate TV shows"
+	bySize     bool
+	byHash     bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dupes",
+		Aliases: []string{"dup"},
+		Short:   dupesDesc,
+		Long:    dupesDesc + ", comparing names regardless of case, diacritics and punctuation.",
+		Args:    cobra.MaximumNArgs(0),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdutil.CallParentPersistentPreRunE(cmd.Parent(), args)
+			if err != nil {
+				return err
+			}
+
+			err = cmdutil.OnlyValidOutputs()
+			if err != nil {
+				return err
+			}
+
+			err = svc.SFTP.Connect()
+			if err != nil {
+				return fmt.Errorf("failed to connect to SFTP server: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			options := []string{
+				"movies",
+				"tvshows",
+				"animes",
+			}
+
+			selectedOption, _ := pterm.DefaultInteractiveSelect.
+				WithDefaultText("Select media type").
+				WithOptions(options).
+				Show()
+
+			var subCmd *cobra.Command
+			switch selectedOption {
+			case "movies":
+				subCmd = newMovieCmd()
+
+			case "tvshows":
+				subCmd = newTVShowCmd()
+
+			case "animes":
+				subCmd = newAnimeCmd()
+			}
+
+			fmt.Fprintln(out)
+
+			if err := subCmd.RunE(cmd, args); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&bySize, "by-size", false, "only report duplicates whose video files have the same size")
+	cmd.PersistentFlags().BoolVar(&byHash, "by-hash", false, "only report duplicates whose video files have the same checksum")
+	cmdutil.AddPersistentOutputFlag(cmd)
+
+	cmd.AddCommand(newAnimeCmd())
+	cmd.AddCommand(newMovieCmd())
+	cmd.AddCommand(newTVShowCmd())
+
+	return cmd
+}
+
+func newAnimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "animes",
+		Aliases: []string{"anime", "ani", "a"},
+		Short:   animeDesc,
+		Long:    animeDesc + ", reporting episodes stored several times as animes may be split across destinations.",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), media.KindAnime)
+		},
+	}
+
+	return cmd
+}
+
+func newMovieCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "movies",
+		Aliases: []string{"movie", "mov", "m"},
+		Short:   movieDesc,
+		Long:    movieDesc + ".",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), media.KindMovie)
+		},
+	}
+
+	return cmd
+}
+
+func newTVShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tvshows",
+		Aliases: []string{"tvshow", "tv", "t"},
+		Short:   tvShowDesc,
+		Long:    tvShowDesc + ", reporting episodes stored several times as TV shows may be split across destinations.",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), media.KindTVShow)
+		},
+	}
+
+	return cmd
+}
+
+// Media stored in several folders. For shows, only the episodes found in several of them are duplicates.
+type duplicate struct {
+	Name      string      `json:"name" yaml:"name"`
+	Episodes  []string    `json:"episodes,omitempty" yaml:"episodes,omitempty"`
+	Locations []*location `json:"locations" yaml:"locations"`
+}
+
+// Folder holding a duplicate, along with the total size and checksums of its video files.
+type location struct {
+	Path   string   `json:"path" yaml:"path"`
+	Size   int64    `json:"size" yaml:"size"`
+	Hashes []string `json:"hashes,omitempty" yaml:"hashes,omitempty"`
+
+	videos   []string
+	episodes []string
+}
+
+func process(ctx context.Context, out io.Writer, kind media.Kind) error {
+	spinner, err := pterm.DefaultSpinner.Start("Loading information...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	items, err := remote.List(kind)
+	if err != nil {
+		return err
+	}
+
+	var duplicates []*duplicate
+	for _, d := range remote.FindDuplicates(items) {
+		locations, err := loadLocations(ctx, d.Items)
+		if err != nil {
+			return err
+		}
+		for _, group := range groupLocations(locations, bySize) {
+			if kind == media.KindMovie {
+				duplicates = append(duplicates, &duplicate{Name: d.Items[0].Name, Locations: group})
+				continue
+			}
+			// Seasons of a show may be split across destinations, so a same name alone does not make a duplicate.
+			if dup := findEpisodeDuplicate(d.Items[0].Name, group); dup != nil {
+				duplicates = append(duplicates, dup)
+			}
+		}
+	}
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	if cmdutil.OutputFormat != "text" {
+		return cmdutil.PrintStructured(out, lo.Ternary(duplicates == nil, []*duplicate{}, duplicates))
+	}
+
+	if len(duplicates) == 0 {
+		pterm.Success.Println("No duplicates found")
+		return nil
+	}
+
+	lw := cmdutil.NewListWriter()
+	for _, d := range duplicates {
+		if len(d.Episodes) > 0 {
+			lw.AppendItem(fmt.Sprintf("%s %s", d.Name, pterm.Gray("["+shortEpisodes(d.Episodes)+"]")))
+		} else {
+			lw.AppendItem(d.Name)
+		}
+		lw.Indent()
+		for _, l := range d.Locations {
+			details := util.FormatBytes(l.Size)
+			if len(l.Hashes) > 0 {
+				details += ", " + shortHash(l.Hashes)
+			}
+			lw.AppendItem(fmt.Sprintf("%s %s", l.Path, pterm.Gray("["+details+"]")))
+		}
+		lw.UnIndent()
+	}
+	fmt.Fprintln(out, lw.Render())
+	fmt.Fprintln(out)
+	pterm.Warning.Printfln("Found %d duplicate(s)", len(duplicates))
+
+	return nil
+}
+
+// Reads size, episodes, and checksums when asked to, of video files held by given items.
+func loadLocations(ctx context.Context, items []*remote.Item) ([]*location, error) {
+	eg, _ := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]*location, len(items))
+	for i, item := range items {
+		eg.Go(func() error {
+			l := &location{Path: item.Path()}
+
+			walker := client.Walk(item.Path())
+			for walker.Step() {
+				if err := walker.Err(); err != nil {
+					return fmt.Errorf("failed to read %s: %w", walker.Path(), err)
+				}
+				if walker.Stat().IsDir() || !util.IsVideo(walker.Path()) {
+					continue
+				}
+				l.Size += walker.Stat().Size()
+				l.videos = append(l.videos, walker.Path())
+
+				_, seasonNumber, episodeNumber, _, err := media.ParseEpisodeFilename(walker.Stat().Name())
+				if err == nil {
+					l.episodes = append(l.episodes, episodeCode(seasonNumber, episodeNumber))
+				}
+			}
+			l.episodes = lo.Uniq(l.episodes)
+
+			locations[i] = l
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	if byHash {
+		if err := loadHashes(ctx, locations); err != nil {
+			return nil, err
+		}
+	}
+
+	return locations, nil
+}
+
+// Computes checksums of video files held by given locations, all of them being sent concurrently.
+func loadHashes(ctx context.Context, locations []*location) error {
+	eg, _ := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+
+	for _, l := range locations {
+		l.Hashes = make([]string, len(l.videos))
+		for i, video := range l.videos {
+			eg.Go(func() error {
+				hash, err := remote.Checksum(video)
+				if err != nil {
+					return fmt.Errorf("failed to compute checksum of %s: %w", video, err)
+				}
+				l.Hashes[i] = hash
+				return nil
+			})
+		}
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	for _, l := range locations {
+		slices.Sort(l.Hashes)
+	}
+
+	return nil
+}
+
+// Splits given locations by size and checksums when asked to, keeping only groups holding several locations.
+func groupLocations(locations []*location, compareSize bool) [][]*location {
+	if !compareSize && !byHash {
+		return [][]*location{locations}
+	}
+
+	keyOf := func(l *location) string {
+		key := ""
+		if compareSize {
+			key += strconv.FormatInt(l.Size, 10)
+		}
+		if byHash {
+			key += "/" + strings.Join(l.Hashes, ",")
+		}
+		return key
+	}
+	groups := lo.GroupBy(locations, keyOf)
+
+	// Walks locations rather than the map to keep a stable order.
+	var result [][]*location
+	for _, l := range locations {
+		key := keyOf(l)
+		if group := groups[key]; len(group) > 1 {
+			result = append(result, group)
+		}
+		delete(groups, key)
+	}
+
+	return result
+}
+
+// Returns the duplicate made of given show locations holding the same episodes, along with those episodes, or nil if
+// none of them is stored several times.
+func findEpisodeDuplicate(name string, locations []*location) *duplicate {
+	counts := map[string]int{}
+	for _, l := range locations {
+		for _, episode := range l.episodes {
+			counts[episode]++
+		}
+	}
+	isDuplicate := func(episode string) bool { return counts[episode] > 1 }
+
+	d := &duplicate{Name: name}
+	for episode := range counts {
+		if isDuplicate(episode) {
+			d.Episodes = append(d.Episodes, episode)
+		}
+	}
+	if len(d.Episodes) == 0 {
+		return nil
+	}
+	slices.Sort(d.Episodes)
+
+	for _, l := range locations {
+		if slices.ContainsFunc(l.episodes, isDuplicate) {
+			d.Locations = append(d.Locations, l)
+		}
+	}
+
+	return d
+}
+
+func episodeCode(seasonNumber, episodeNumber int) string {
+	return fmt.Sprintf("S%02dE%02d", seasonNumber, episodeNumber)
+}
+
+func shortEpisodes(episodes []string) string {
+	if len(episodes) > 3 {
+		return fmt.Sprintf("%d episodes, %s to %s", len(episodes), episodes[0], episodes[len(episodes)-1])
+	}
+	return strings.Join(episodes, ", ")
+}
+
+func shortHash(hashes []string) string {
+	if len(hashes) > 1 {
+		return fmt.Sprintf("%d checksums", len(hashes))
+	}
+	if len(hashes[0]) > 12 {
+		return hashes[0][:12]
+	}
+	return hashes[0]
+}
@@ -0,0 +1,107 @@
+package dupes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+func setup(t *testing.T) *servicetest.Server {
+	t.Helper()
+
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/movies/Amélie (2001)/Amélie (2001).mkv", "content")
+	server.WriteFile(t, "/volume2/movies/Amelie (2001)/Amelie (2001).mkv", "other content")
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "content")
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/poster.jpg", "poster")
+	server.WriteFile(t, "/volume2/movies/Movie (2024)/Movie (2024).mkv", "content")
+	server.WriteFile(t, "/volume2/movies/Unique (2020)/Unique (2020).mkv", "content")
+
+	configtest.Set(t, map[string]any{
+		config.KeySCPDestMoviesPaths: []string{"/volume1/movies", "/volume2/movies"},
+	})
+	previousFormat := cmdutil.OutputFormat
+	cmdutil.OutputFormat = "json"
+	t.Cleanup(func() {
+		cmdutil.OutputFormat = previousFormat
+		bySize, byHash = false, false
+	})
+	require.NoError(t, svc.SFTP.Connect())
+
+	return server
+}
+
+func run(t *testing.T, kind media.Kind) []*duplicate {
+	t.Helper()
+
+	out := new(bytes.Buffer)
+	require.NoError(t, process(context.Background(), out, kind))
+	var duplicates []*duplicate
+	require.NoError(t, json.Unmarshal(out.Bytes(), &duplicates))
+	return duplicates
+}
+
+func Test_Dupes_Reports_Movies_Across_Destinations(t *testing.T) {
+	setup(t)
+
+	duplicates := run(t, media.KindMovie)
+
+	require.Len(t, duplicates, 2)
+	assert.Equal(t, "Amélie (2001)", duplicates[0].Name)
+	assert.Equal(t, []*location{
+		{Path: "/volume1/movies/Amélie (2001)", Size: 7},
+		{Path: "/volume2/movies/Amelie (2001)", Size: 13},
+	}, duplicates[0].Locations)
+	assert.Equal(t, "Movie (2024)", duplicates[1].Name)
+}
+
+func Test_Dupes_Compares_Sizes_And_Checksums(t *testing.T) {
+	setup(t)
+	bySize, byHash = true, true
+
+	duplicates := run(t, media.KindMovie)
+
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "Movie (2024)", duplicates[0].Name)
+	require.Len(t, duplicates[0].Locations, 2)
+	assert.Equal(t, duplicates[0].Locations[0].Hashes, duplicates[0].Locations[1].Hashes)
+	assert.Len(t, duplicates[0].Locations[0].Hashes, 1)
+}
+
+func Test_Dupes_Reports_Overlapping_TV_Show_Episodes(t *testing.T) {
+	server := setup(t)
+	server.WriteFile(t, "/volume1/tvshows/Split/Season 1/Split - S01E01.mkv", "season 1")
+	server.WriteFile(t, "/volume2/tvshows/Split/Season 2/Split - S02E01.mkv", "season 2 episode")
+	server.WriteFile(t, "/volume1/tvshows/Copied/Season 1/Copied - S01E01.mkv", "content")
+	server.WriteFile(t, "/volume2/tvshows/Copied/Season 1/Copied - S01E01.mkv", "content")
+	server.WriteFile(t, "/volume1/tvshows/Partial/Season 1/Partial - S01E01.mkv", "episode 1")
+	server.WriteFile(t, "/volume1/tvshows/Partial/Season 1/Partial - S01E02.mkv", "episode 2")
+	server.WriteFile(t, "/volume2/tvshows/Partial/Season 1/Partial - S01E02.mp4", "other episode 2")
+	server.WriteFile(t, "/volume2/tvshows/Partial/Season 2/Partial - S02E01.mkv", "season 2")
+	configtest.Set(t, map[string]any{
+		config.KeySCPDestTVShowsPaths: []string{"/volume1/tvshows", "/volume2/tvshows"},
+	})
+
+	duplicates := run(t, media.KindTVShow)
+
+	require.Len(t, duplicates, 2)
+	assert.Equal(t, "Copied", duplicates[0].Name)
+	assert.Equal(t, []string{"S01E01"}, duplicates[0].Episodes)
+	assert.Equal(t, "Partial", duplicates[1].Name)
+	assert.Equal(t, []string{"S01E02"}, duplicates[1].Episodes)
+	assert.Equal(t, []*location{
+		{Path: "/volume1/tvshows/Partial", Size: 18},
+		{Path: "/volume2/tvshows/Partial", Size: 23},
+	}, duplicates[1].Locations)
+}
@@ -0,0 +1,75 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+// Library item whose artwork can be set.
+type Item struct {
+	RatingKey string
+	Title     string
+}
+
+// Returns items of the library holding given kind of media, indexed by the full path of their folders. Items stored
+// in several folders, such as shows split across pools, are indexed under each of them.
+func IndexItemsByLocation(ctx context.Context, kind plex.LibraryKind) (map[string]*Item, error) {
+	libID, err := plexService().LibraryID(ctx, kind)
+	if err != nil {
+		return nil, fmt.Errorf("could not get %s library ID: %w", kind, err)
+	}
+
+	var itemList apiShowList
+	if err := plexService().Get(ctx, fmt.Sprintf("/library/sections/%d/all", libID), &itemList); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+	mu := sync.Mutex{}
+	items := make(map[string]*Item, len(itemList.MediaContainer.Metadata))
+
+	for _, item := range itemList.MediaContainer.Metadata {
+		eg.Go(func() error {
+			var meta apiMetadataResponse
+			if err := plexService().Get(egCtx, "/library/metadata/"+item.RatingKey, &meta); err != nil {
+				return fmt.Errorf("failed to get metadata for %q: %w", item.Title, err)
+			}
+
+			folderPaths := metadataFolderPaths(&meta)
+			if len(folderPaths) == 0 {
+				return nil
+			}
+
+			mu.Lock()
+			for _, folderPath := range folderPaths {
+				items[folderPath] = &Item{RatingKey: item.RatingKey, Title: item.Title}
+			}
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Uploads given JPEG image as the poster of given item.
+func UploadPoster(ctx context.Context, ratingKey string, content io.Reader) error {
+	return plexService().UploadPoster(ctx, ratingKey, content)
+}
+
+// Uploads given JPEG image as the background of given item.
+func UploadBackground(ctx context.Context, ratingKey string, content io.Reader) error {
+	return plexService().UploadArt(ctx, ratingKey, content)
+}
@@ -0,0 +1,147 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
+)
+
+type MovieDetails struct {
+	Path            string
+	DBIDs           []*ID
+	HasMatchingFile bool
+}
+
+// Returns remote movie folders indexed by name. A name maps to several folders when it exists in multiple configured
+// destinations.
+func GetMoviesDetails() (folders map[string][]*MovieDetails, err error) {
+	moviesPaths := viper.GetStringSlice(config.KeySCPDestMoviesPaths)
+	if len(moviesPaths) == 0 {
+		return nil, fmt.Errorf("%s configuration entry is missing", config.KeySCPDestMoviesPaths)
+	}
+
+	folders = make(map[string][]*MovieDetails)
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, moviesPath := range moviesPaths {
+		entries, err := client.ReadDir(moviesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read remote movies directory %q: %w", moviesPath, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				movieDetails := &MovieDetails{
+					Path: filepath.Join(moviesPath, entry.Name()),
+				}
+				folders[entry.Name()] = append(folders[entry.Name()], movieDetails)
+
+				matchFile, err := readMatchingFile(filepath.Join(movieDetails.Path, plex.MovieMatchingFileName))
+				if err != nil {
+					return nil, err
+				}
+				movieDetails.DBIDs = matchingFileIDs(matchFile)
+				movieDetails.HasMatchingFile = matchFile != nil
+			}
+		}
+	}
+
+	return folders, nil
+}
+
+func SortMovies(remoteMovies []*Movie) {
+	slices.SortFunc(remoteMovies, func(a, b *Movie) int {
+		aName := strings.ToLower(a.Name)
+		bName := strings.ToLower(b.Name)
+		if aName < bName {
+			return -1
+		}
+		if aName > bName {
+			return 1
+		}
+		return a.Year - b.Year
+	})
+}
+
+func WriteMovieMatchingFile(movie *Movie, remotePath string) error {
+	return writeMatchingFile(movie.IDs, filepath.Join(remotePath, plex.MovieMatchingFileName))
+}
+
+func FetchPlexMovies(ctx context.Context) ([]*apiMovie, error) {
+	libID, err := plexService().LibraryID(ctx, plex.LibraryKindMovies)
+	if err != nil {
+		return nil, fmt.Errorf("could not get movies library ID: %w", err)
+	}
+
+	var movieList apiMovieList
+	if err := plexService().Get(ctx, fmt.Sprintf("/library/sections/%d/all", libID), &movieList); err != nil {
+		return nil, fmt.Errorf("failed to list movies: %w", err)
+	}
+
+	return movieList.MediaContainer.Metadata, nil
+}
+
+func GetMovieDetails(ctx context.Context, title string, year int, ratingKey string) (*Movie, error) {
+	var meta apiMetadataResponse
+	if err := plexService().Get(ctx, "/library/metadata/"+ratingKey, &meta); err != nil {
+		return nil, fmt.Errorf("failed to get metadata for %q: %w", title, err)
+	}
+
+	movie := &Movie{
+		Name: title,
+		Year: year,
+	}
+
+	if len(meta.MediaContainer.Metadata) > 0 {
+		movie.Description = meta.MediaContainer.Metadata[0].Summary
+
+		ids, err := parseGUIDs(meta.MediaContainer.Metadata[0].GUIDs, title)
+		if err != nil {
+			return nil, err
+		}
+		movie.IDs = ids
+
+		movie.FolderNames = metadataFolderNames(&meta)
+	}
+
+	if len(movie.FolderNames) == 0 {
+		return nil, fmt.Errorf("could not find folder name for %q", title)
+	}
+
+	if len(movie.IDs) == 0 {
+		return nil, fmt.Errorf("could not find IDs for %q", title)
+	}
+
+	return movie, nil
+}
+
+type Movie struct {
+	Description string
+	FolderNames []string
+	IDs         []*ID
+	Name        string
+	Year        int
+}
+
+type apiMovie struct {
+	RatingKey string `json:"ratingKey"`
+	Title     string `json:"title"`
+	Year      int    `json:"year"`
+}
+
+type apiMovieList struct {
+	MediaContainer struct {
+		Metadata []*apiMovie `json:"Metadata"`
+	} `json:"MediaContainer"`
+}
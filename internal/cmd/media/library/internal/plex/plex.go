@@ -0,0 +1,422 @@
+package plex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
+)
+
+type ShowsKind media.Kind
+
+const (
+	ShowsKindAnime  ShowsKind = ShowsKind(media.KindAnime)
+	ShowsKindTVShow ShowsKind = ShowsKind(media.KindTVShow)
+)
+
+func (k ShowsKind) String() string {
+	return string(k)
+}
+
+func (k ShowsKind) DisplayText() string {
+	switch k {
+	case ShowsKindAnime:
+		return "anime"
+	case ShowsKindTVShow:
+		return "TV show"
+	default:
+		return "unknown"
+	}
+}
+
+type ShowDetails struct {
+	Path            string
+	DBIDs           []*ID
+	HasMatchingFile bool
+}
+
+// Returns remote show folders indexed by name. A name maps to several folders when a show is split across multiple
+// configured destinations.
+func GetShowsDetails(kind ShowsKind) (folders map[string][]*ShowDetails, err error) {
+	var showsPaths []string
+	switch kind {
+	case ShowsKindAnime:
+		showsPaths = viper.GetStringSlice(config.KeySCPDestAnimesPaths)
+	case ShowsKindTVShow:
+		showsPaths = viper.GetStringSlice(config.KeySCPDestTVShowsPaths)
+	default:
+		return nil, fmt.Errorf("unsupported shows kind: %s", kind)
+	}
+
+	if len(showsPaths) == 0 {
+		return nil, fmt.Errorf("%s configuration entry is missing", config.KeySCPDestTVShowsPaths)
+	}
+
+	folders = make(map[string][]*ShowDetails)
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, showsPath := range showsPaths {
+		entries, err := client.ReadDir(showsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read remote %ss directory %q: %w", kind.DisplayText(), showsPath, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				showDetails := &ShowDetails{
+					Path: filepath.Join(showsPath, entry.Name()),
+				}
+				folders[entry.Name()] = append(folders[entry.Name()], showDetails)
+
+				matchFile, err := readMatchingFile(filepath.Join(showDetails.Path, plex.ShowMatchingFileName))
+				if err != nil {
+					return nil, err
+				}
+				showDetails.DBIDs = matchingFileIDs(matchFile)
+				showDetails.HasMatchingFile = matchFile != nil
+			}
+		}
+	}
+
+	return folders, nil
+}
+
+func SortShows(remoteShows []*Show) {
+	slices.SortFunc(remoteShows, func(a, b *Show) int {
+		aName := strings.ToLower(a.Name)
+		bName := strings.ToLower(b.Name)
+		if aName < bName {
+			return -1
+		}
+		if aName > bName {
+			return 1
+		}
+		return 0
+	})
+}
+
+func WriteShowMatchingFile(show *Show, remotePath string) error {
+	return writeMatchingFile(show.IDs, filepath.Join(remotePath, plex.ShowMatchingFileName))
+}
+
+// Merges given database IDs into the remote matching file, keeping any other existing entry.
+func writeMatchingFile(ids []*ID, remoteFilePath string) error {
+	matchFile, err := readMatchingFile(remoteFilePath)
+	if err != nil {
+		return err
+	}
+	if matchFile == nil {
+		matchFile = &plex.MatchFile{}
+	}
+	matchFile.SetIDs(lo.Map(ids, func(id *ID, _ int) *plex.MatchID {
+		return &plex.MatchID{Identifier: id.Identifier, Value: id.Value}
+	}))
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	remoteFile, err := client.Create(remoteFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create matching file %q: %w", remoteFilePath, err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := remoteFile.Write([]byte(matchFile.String())); err != nil {
+		return fmt.Errorf("failed to write to matching file %q: %w", remoteFilePath, err)
+	}
+
+	if err := client.Chmod(remoteFilePath, config.FileMode); err != nil {
+		return fmt.Errorf("failed to set permissions for matching file %q: %w", remoteFilePath, err)
+	}
+
+	err = client.Chown(
+		remoteFilePath,
+		viper.GetInt(config.KeySCPChownUID),
+		viper.GetInt(config.KeySCPChownGID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set ownership for matching file %q: %w", remoteFilePath, err)
+	}
+
+	return nil
+}
+
+func FetchPlexShows(ctx context.Context, kind ShowsKind) ([]*apiShow, error) {
+	var libraryKind plex.LibraryKind
+	switch kind {
+	case ShowsKindAnime:
+		libraryKind = plex.LibraryKindAnimes
+	case ShowsKindTVShow:
+		libraryKind = plex.LibraryKindTVShows
+	default:
+		return nil, fmt.Errorf("unsupported shows kind: %s", kind)
+	}
+
+	libID, err := plexService().LibraryID(ctx, libraryKind)
+	if err != nil {
+		return nil, fmt.Errorf("could not get %ss library ID: %w", kind.DisplayText(), err)
+	}
+
+	var showList apiShowList
+	if err := plexService().Get(ctx, fmt.Sprintf("/library/sections/%d/all", libID), &showList); err != nil {
+		return nil, fmt.Errorf("failed to list %ss: %w", kind.DisplayText(), err)
+	}
+
+	return showList.MediaContainer.Metadata, nil
+}
+
+func GetShowDetails(ctx context.Context, title, ratingKey string) (*Show, error) {
+	var meta apiMetadataResponse
+	if err := plexService().Get(ctx, "/library/metadata/"+ratingKey, &meta); err != nil {
+		return nil, fmt.Errorf("failed to get metadata for %q: %w", title, err)
+	}
+
+	tvShow := &Show{
+		Name: title,
+	}
+
+	if len(meta.MediaContainer.Metadata) > 0 {
+		tvShow.Description = meta.MediaContainer.Metadata[0].Summary
+
+		ids, err := parseGUIDs(meta.MediaContainer.Metadata[0].GUIDs, title)
+		if err != nil {
+			return nil, err
+		}
+		tvShow.IDs = ids
+
+		tvShow.FolderNames = metadataFolderNames(&meta)
+	}
+
+	if len(tvShow.FolderNames) == 0 {
+		return nil, fmt.Errorf("could not find folder name for %q", title)
+	}
+
+	if len(tvShow.IDs) == 0 {
+		return nil, fmt.Errorf("could not find IDs for %q", title)
+	}
+
+	return tvShow, nil
+}
+
+var (
+	apiGUIDRegex = regexp.MustCompile(`^(?<kind>.+)://(?<id>.+)$`)
+)
+
+// Reads given remote matching file. Returns nil if it does not exist.
+func readMatchingFile(remoteFilePath string) (*plex.MatchFile, error) {
+	remoteDir := filepath.Dir(remoteFilePath)
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	plexMatchFile, err := client.Open(remoteFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open matching file in %q: %w", remoteDir, err)
+	}
+	defer plexMatchFile.Close()
+
+	matchFile, err := plex.ParseMatchFile(plexMatchFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse matching file in %q: %w", remoteDir, err)
+	}
+
+	return matchFile, nil
+}
+
+// Returns sorted database IDs of given matching file, if any.
+func matchingFileIDs(matchFile *plex.MatchFile) []*ID {
+	if matchFile == nil {
+		return nil
+	}
+
+	var ids []*ID
+	for _, id := range matchFile.IDs() {
+		ids = append(ids, &ID{
+			Identifier: id.Identifier,
+			Value:      id.Value,
+		})
+	}
+	sortIDs(ids)
+
+	return ids
+}
+
+// Converts Plex GUIDs such as "imdb://tt0000000" into sorted database IDs.
+func parseGUIDs(guids apiGUIDList, title string) ([]*ID, error) {
+	var ids []*ID
+	for _, guid := range guids {
+		matches := apiGUIDRegex.FindStringSubmatch(guid.ID)
+		if matches == nil || len(matches) != 3 {
+			return nil, fmt.Errorf("could not parse GUID %q for %q", guid.ID, title)
+		}
+		ids = append(ids, &ID{
+			Identifier: strings.ToLower(matches[1]),
+			Value:      matches[2],
+		})
+	}
+	sortIDs(ids)
+
+	return ids, nil
+}
+
+type ID struct {
+	Identifier string `json:"identifier" yaml:"identifier"`
+	Value      string `json:"value" yaml:"value"`
+}
+
+type Show struct {
+	Description string
+	FolderNames []string
+	IDs         []*ID
+	Name        string
+}
+
+type apiShow struct {
+	RatingKey string `json:"ratingKey"`
+	Title     string `json:"title"`
+}
+
+type apiShowList struct {
+	MediaContainer struct {
+		Metadata []*apiShow `json:"Metadata"`
+	} `json:"MediaContainer"`
+}
+
+type apiGUID struct {
+	ID string `json:"id"`
+}
+
+type apiGUIDList []apiGUID
+
+func (g *apiGUIDList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*g = apiGUIDList{{ID: single}}
+		return nil
+	}
+
+	var arr []apiGUID
+	if err := json.Unmarshal(data, &arr); err == nil {
+		*g = arr
+		return nil
+	}
+
+	return fmt.Errorf("APIGUIDList: unsupported format: %s", string(data))
+}
+
+type apiMetadataResponse struct {
+	MediaContainer struct {
+		Metadata []struct {
+			GUIDs     apiGUIDList `json:"Guid"`
+			Locations []struct {
+				Path string `json:"path"`
+			} `json:"Location"`
+			Media []struct {
+				Parts []struct {
+					File string `json:"file"`
+				} `json:"Part"`
+			} `json:"Media"`
+			Summary string `json:"summary"`
+		} `json:"Metadata"`
+	} `json:"MediaContainer"`
+}
+
+// Returns the name of the folder holding the first item of given metadata, or an empty string if unknown.
+func metadataFolderName(meta *apiMetadataResponse) string {
+	folderNames := metadataFolderNames(meta)
+	if len(folderNames) == 0 {
+		return ""
+	}
+	return folderNames[0]
+}
+
+// Returns the distinct names of the folders holding the first item of given metadata, in the order Plex lists them.
+func metadataFolderNames(meta *apiMetadataResponse) []string {
+	return lo.Uniq(lo.Map(metadataFolderPaths(meta), func(path string, _ int) string {
+		return filepath.Base(path)
+	}))
+}
+
+// Returns the distinct paths of the folders holding the first item of given metadata, in the order Plex lists them.
+func metadataFolderPaths(meta *apiMetadataResponse) []string {
+	if len(meta.MediaContainer.Metadata) == 0 {
+		return nil
+	}
+	metadata := meta.MediaContainer.Metadata[0]
+
+	var folderPaths []string
+	for _, location := range metadata.Locations {
+		folderPaths = append(folderPaths, filepath.Clean(location.Path))
+	}
+
+	// Movies do not expose a location like shows do, so folders are derived from the media files instead.
+	if len(folderPaths) == 0 {
+		for _, m := range metadata.Media {
+			for _, part := range m.Parts {
+				if part.File != "" {
+					folderPaths = append(folderPaths, filepath.Dir(part.File))
+				}
+			}
+		}
+	}
+
+	return lo.Uniq(folderPaths)
+}
+
+func sortIDs(ids []*ID) {
+	slices.SortFunc(ids, func(a, b *ID) int {
+		aIdentifier := strings.ToLower(a.Identifier)
+		bIdentifier := strings.ToLower(b.Identifier)
+		if aIdentifier < bIdentifier {
+			return -1
+		}
+		if aIdentifier > bIdentifier {
+			return 1
+		}
+		return 0
+	})
+}
+
+var (
+	plexMu  sync.Mutex
+	plexSVC *plex.Service
+
+	// API URL and token the service was created with, so that it is created again once they change.
+	plexSVCURL   string
+	plexSVCToken string
+)
+
+func plexService() *plex.Service {
+	plexMu.Lock()
+	defer plexMu.Unlock()
+
+	url, token := viper.GetString(config.KeyPlexAPIURL), viper.GetString(config.KeyPlexAPIToken)
+	if plexSVC == nil || plexSVCURL != url || plexSVCToken != token {
+		plexSVC = plex.NewServiceFromConfig()
+		plexSVCURL, plexSVCToken = url, token
+	}
+	return plexSVC
+}
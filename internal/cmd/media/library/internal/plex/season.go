@@ -0,0 +1,52 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+)
+
+type Season struct {
+	Index         int
+	RatingKey     string
+	Title         string
+	EpisodesCount int
+}
+
+// Returns the folder name of given show along with its seasons as known by Plex.
+func GetShowSeasons(ctx context.Context, title, ratingKey string) (folderName string, seasons []*Season, err error) {
+	var meta apiMetadataResponse
+	if err := plexService().Get(ctx, "/library/metadata/"+ratingKey, &meta); err != nil {
+		return "", nil, fmt.Errorf("failed to get metadata for %q: %w", title, err)
+	}
+	folderName = metadataFolderName(&meta)
+	if folderName == "" {
+		return "", nil, fmt.Errorf("could not find folder name for %q", title)
+	}
+
+	var children apiSeasonList
+	if err := plexService().Get(ctx, "/library/metadata/"+ratingKey+"/children", &children); err != nil {
+		return "", nil, fmt.Errorf("failed to get seasons for %q: %w", title, err)
+	}
+
+	for _, season := range children.MediaContainer.Metadata {
+		seasons = append(seasons, &Season{
+			Index:         season.Index,
+			RatingKey:     season.RatingKey,
+			Title:         season.Title,
+			EpisodesCount: season.LeafCount,
+		})
+	}
+
+	return folderName, seasons, nil
+}
+
+type apiSeasonList struct {
+	MediaContainer struct {
+		Metadata []struct {
+			Index     int    `json:"index"`
+			LeafCount int    `json:"leafCount"`
+			RatingKey string `json:"ratingKey"`
+			Title     string `json:"title"`
+		} `json:"Metadata"`
+	} `json:"MediaContainer"`
+}
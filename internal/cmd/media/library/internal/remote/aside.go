@@ -0,0 +1,74 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+)
+
+// Remote file moved aside in its folder while a new file is written at its path, so that it can be put back if writing
+// fails.
+type AsideFile struct {
+	Path string
+
+	aside string
+}
+
+// Moves given remote file aside, next to where it was stored.
+func SetAside(path string) (*AsideFile, error) {
+	f := &AsideFile{
+		Path:  path,
+		aside: filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.replaced", filepath.Base(path))),
+	}
+
+	found, err := exists(f.aside)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return nil, fmt.Errorf("failed to move %s aside: %s already exists", path, f.aside)
+	}
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.PosixRename(f.Path, f.aside); err != nil {
+		return nil, fmt.Errorf("failed to move %s aside: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Moves the file back to its path, overwriting whatever was written there meanwhile.
+func (f *AsideFile) Restore() error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	if err := client.PosixRename(f.aside, f.Path); err != nil {
+		return fmt.Errorf("failed to restore %s from %s: %w", f.Path, f.aside, err)
+	}
+	return nil
+}
+
+// Removes the file for good.
+func (f *AsideFile) Remove() error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	if err := client.Remove(f.aside); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove replaced file %s: %w", f.aside, err)
+	}
+	return nil
+}
+
+// Moves the file into given trash folder, as if it were still stored at its path.
+func (f *AsideFile) MoveToTrash(trash string) error {
+	return moveToTrash(trash, f.aside, f.Path)
+}
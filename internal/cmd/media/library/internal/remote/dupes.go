@@ -0,0 +1,99 @@
+package remote
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/samber/lo"
+
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+// Folders holding the same media, found under one or several configured destinations.
+type Duplicate struct {
+	Key   string
+	Items []*Item
+}
+
+// Returns every media folder of given kind across the configured destinations.
+func List(kind media.Kind) ([]*Item, error) {
+	roots, err := Roots(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range roots {
+		entries, err := client.ReadDir(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", root, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				items = append(items, &Item{Root: root, Name: entry.Name()})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// Returns the key identifying given folder name regardless of case, diacritics and punctuation. Names following the
+// "<Title> (<Year>)" convention keep their year, so that remakes are not reported as duplicates.
+func NormalizeName(name string) string {
+	if title, year, err := media.ParseMovieName(name); err == nil {
+		return fmt.Sprintf("%s (%d)", normalize(title), year)
+	}
+	return normalize(name)
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(util.RemoveDiacritics(s))
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Groups given items by normalized name, keeping only groups holding several items.
+func FindDuplicates(items []*Item) []*Duplicate {
+	var duplicates []*Duplicate
+	for key, group := range lo.GroupBy(items, func(item *Item) string { return NormalizeName(item.Name) }) {
+		if len(group) < 2 {
+			continue
+		}
+		slices.SortFunc(group, func(a, b *Item) int {
+			return cmp.Compare(a.Path(), b.Path())
+		})
+		duplicates = append(duplicates, &Duplicate{Key: key, Items: group})
+	}
+
+	slices.SortFunc(duplicates, func(a, b *Duplicate) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return duplicates
+}
+
+// Computes the SHA-256 checksum of given remote file on the server.
+func Checksum(path string) (string, error) {
+	output, err := svc.SSH.SendCommands(fmt.Sprintf("sha256sum %s", util.ShellQuote(path)))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", errors.New("empty output")
+	}
+	return fields[0], nil
+}
@@ -0,0 +1,201 @@
+package remote
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+)
+
+// Returns the plan renaming every location of given movie, along with the files named after it. Given name must
+// follow the "<Title> (<Year>)" naming convention.
+func PlanMovieRename(items []*Item, name string) (*Plan, error) {
+	title, year, err := media.ParseMovieName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid movie name %q: %w", name, err)
+	}
+	name = media.MovieName(title, year)
+	if !isValidName(name) {
+		return nil, fmt.Errorf("invalid movie name %q", name)
+	}
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	pl := &Plan{}
+	for _, item := range items {
+		entries, err := client.ReadDir(item.Path())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", item.Path(), err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			// Covers the movie file as well as its subtitles.
+			rest, ok := strings.CutPrefix(entry.Name(), item.Name+".")
+			if !ok {
+				continue
+			}
+			if err := pl.Rename(item.Path(), entry.Name(), name+"."+rest); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := planItemRename(pl, item, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return pl, nil
+}
+
+// Returns the plan renaming every location of given show, along with its season folders and episodes.
+func PlanShowRename(items []*Item, name string) (*Plan, error) {
+	if !isValidName(name) {
+		return nil, fmt.Errorf("invalid show name %q", name)
+	}
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	pl := &Plan{}
+	for _, item := range items {
+		entries, err := client.ReadDir(item.Path())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", item.Path(), err)
+		}
+
+		for _, entry := range entries {
+			seasonNumber, ok := media.ParseSeasonName(entry.Name())
+			if !entry.IsDir() || !ok {
+				continue
+			}
+			seasonPath := filepath.Join(item.Path(), entry.Name())
+
+			episodes, err := client.ReadDir(seasonPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", seasonPath, err)
+			}
+			for _, episode := range episodes {
+				if episode.IsDir() {
+					continue
+				}
+				newBasename, ok := episodeRename(item.Name, name, episode.Name())
+				if !ok {
+					continue
+				}
+				if err := pl.Rename(seasonPath, episode.Name(), newBasename); err != nil {
+					return nil, err
+				}
+			}
+
+			if err := pl.Rename(item.Path(), entry.Name(), media.SeasonName(seasonNumber)); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := planItemRename(pl, item, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return pl, nil
+}
+
+// Returns the plan moving given item to another configured destination of the same kind.
+func PlanMove(kind media.Kind, item *Item, destination string) (*Plan, error) {
+	roots, err := Roots(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	destination = filepath.Clean(destination)
+	if !slices.Contains(roots, destination) {
+		return nil, fmt.Errorf("%s is not a configured destination, expected one of %s", destination, strings.Join(roots, ", "))
+	}
+	if destination == item.Root {
+		return nil, fmt.Errorf("%s is already in %s", item.Name, destination)
+	}
+
+	to := filepath.Join(destination, item.Name)
+	if err := ensureMissing(to); err != nil {
+		return nil, err
+	}
+
+	pl := &Plan{Targets: []string{to}}
+	pl.add(ActionMove, item.Path(), to)
+
+	return pl, nil
+}
+
+// Returns the plan removing every location of given items.
+func PlanRemove(items []*Item) *Plan {
+	pl := &Plan{}
+	for _, item := range items {
+		pl.add(ActionRemove, item.Path(), "")
+	}
+	return pl
+}
+
+// Returns the new name of given episode file when it belongs to given show. Files following the naming convention
+// are renamed from their parsed season and episode numbers, other files such as subtitles only get their prefix
+// replaced.
+func episodeRename(oldShowName, newShowName, basename string) (string, bool) {
+	showName, seasonNumber, episodeNumber, extension, err := media.ParseEpisodeFilename(basename)
+	if err == nil && showName == oldShowName {
+		return media.EpisodeName(newShowName, seasonNumber, episodeNumber) + "." + extension, true
+	}
+
+	rest, ok := strings.CutPrefix(basename, oldShowName+" - ")
+	if !ok {
+		return "", false
+	}
+	return newShowName + " - " + rest, true
+}
+
+// Adds the rename of given item folder, which must come after renames of its content.
+func planItemRename(pl *Plan, item *Item, name string) error {
+	pl.Targets = append(pl.Targets, filepath.Join(item.Root, name))
+	return pl.Rename(item.Root, item.Name, name)
+}
+
+// Adds the rename of given entry of given folder unless it is already named as expected. Fails if the new name is
+// already taken, either remotely or by another rename of the plan.
+func (pl *Plan) Rename(dir, from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	target := filepath.Join(dir, to)
+	for _, op := range pl.Operations {
+		if op.To == target {
+			return fmt.Errorf("%s is already the target of %s", target, op.From)
+		}
+	}
+	if err := ensureMissing(target); err != nil {
+		return err
+	}
+	pl.add(ActionRename, filepath.Join(dir, from), target)
+
+	return nil
+}
+
+func ensureMissing(path string) error {
+	found, err := exists(path)
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("%s already exists", path)
+	}
+	return nil
+}
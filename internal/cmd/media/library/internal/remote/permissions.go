@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+// Sets given modes on remote entries and gives them to given owner.
+func SetPermissions(ctx context.Context, entries map[string]fs.FileMode, uid, gid int) error {
+	eg, _ := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	for entry, mode := range entries {
+		eg.Go(func() error {
+			return client.Chmod(entry, mode)
+		})
+		eg.Go(func() error {
+			return client.Chown(entry, uid, gid)
+		})
+	}
+
+	return eg.Wait()
+}
+
+// Returns the default modes of given remote folder and everything it contains.
+func TreePermissions(root string) (map[string]fs.FileMode, error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]fs.FileMode{}
+
+	walker := client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", walker.Path(), err)
+		}
+		if walker.Stat().IsDir() {
+			entries[walker.Path()] = config.DirectoryMode
+		} else {
+			entries[walker.Path()] = config.FileMode
+		}
+	}
+
+	return entries, nil
+}
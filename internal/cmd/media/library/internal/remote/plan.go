@@ -0,0 +1,125 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+type Action string
+
+const (
+	ActionMove   Action = "move"
+	ActionRemove Action = "remove"
+	ActionRename Action = "rename"
+)
+
+// Change to apply to a remote file or folder.
+type Operation struct {
+	Action Action
+	From   string
+	To     string
+}
+
+// Operations to apply in order, along with the folders whose permissions must be reset once they are done.
+type Plan struct {
+	Operations []*Operation
+	Targets    []string
+}
+
+func (pl *Plan) add(action Action, from, to string) {
+	pl.Operations = append(pl.Operations, &Operation{Action: action, From: from, To: to})
+}
+
+// Prints operations of given plan.
+func (pl *Plan) Print(out io.Writer) {
+	lw := cmdutil.NewListWriter()
+
+	for _, op := range pl.Operations {
+		switch op.Action {
+		case ActionRemove:
+			lw.AppendItem(fmt.Sprintf("%s %s", pterm.Red(string(op.Action)), op.From))
+		default:
+			lw.AppendItem(fmt.Sprintf("%s %s -> %s", pterm.Yellow(string(op.Action)), op.From, pterm.Green(op.To)))
+		}
+	}
+
+	fmt.Fprintln(out, lw.Render())
+}
+
+// Applies operations of given plan in order, then gives the target folders and their content the configured
+// permissions and owner, as uploads do.
+func (pl *Plan) Apply(ctx context.Context) error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	for _, op := range pl.Operations {
+		var err error
+		switch op.Action {
+		case ActionMove:
+			// Destinations usually live on different pools, which SFTP renames cannot cross.
+			_, err = svc.SSH.SendCommands(fmt.Sprintf("mv -n -- %s %s", util.ShellQuote(op.From), util.ShellQuote(op.To)))
+		case ActionRemove:
+			err = client.RemoveAll(op.From)
+		case ActionRename:
+			err = client.PosixRename(op.From, op.To)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s: %w", op.Action, op.From, err)
+		}
+	}
+
+	uid := viper.GetInt(config.KeySCPChownUID)
+	gid := viper.GetInt(config.KeySCPChownGID)
+	for _, target := range pl.Targets {
+		entries, err := TreePermissions(target)
+		if err != nil {
+			return err
+		}
+		if err := SetPermissions(ctx, entries, uid, gid); err != nil {
+			return fmt.Errorf("failed to set permissions of %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// Prints given plan then applies it once confirmed. Nothing is applied when dry-run is set.
+func Run(ctx context.Context, out io.Writer, pl *Plan, p prompt.Prompter, dryRun bool) error {
+	if len(pl.Operations) == 0 {
+		pterm.Success.Println("Nothing to do")
+		return nil
+	}
+
+	pl.Print(out)
+	if dryRun {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	confirmed, err := p.Confirm(fmt.Sprintf("Apply %d change(s)?", len(pl.Operations)), false)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return nil
+	}
+
+	if err := pl.Apply(ctx); err != nil {
+		return err
+	}
+	pterm.Success.Printfln("Applied %d change(s)", len(pl.Operations))
+
+	return nil
+}
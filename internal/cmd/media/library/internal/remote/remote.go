@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+)
+
+// Media item folder found in one of the configured destinations.
+type Item struct {
+	Root string
+	Name string
+}
+
+func (i *Item) Path() string {
+	return filepath.Join(i.Root, i.Name)
+}
+
+// Returns the configured destinations holding given kind of media.
+func Roots(kind media.Kind) ([]string, error) {
+	var key string
+	switch kind {
+	case media.KindAnime:
+		key = config.KeySCPDestAnimesPaths
+	case media.KindMovie:
+		key = config.KeySCPDestMoviesPaths
+	case media.KindTVShow:
+		key = config.KeySCPDestTVShowsPaths
+	default:
+		return nil, fmt.Errorf("unsupported media kind: %s", kind)
+	}
+
+	roots := viper.GetStringSlice(key)
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%s configuration entry is missing", key)
+	}
+
+	cleaned := make([]string, len(roots))
+	for i, root := range roots {
+		cleaned[i] = filepath.Clean(root)
+	}
+	return cleaned, nil
+}
+
+// Returns every folder with given name across the configured destinations. A show split across several destinations
+// is found once per destination.
+func Find(kind media.Kind, name string) ([]*Item, error) {
+	if !isValidName(name) {
+		return nil, fmt.Errorf("invalid name %q", name)
+	}
+
+	roots, err := Roots(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range roots {
+		item := &Item{Root: root, Name: name}
+		info, err := client.Stat(item.Path())
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", item.Path(), err)
+		}
+		if info.IsDir() {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("could not find %s in %s", name, strings.Join(roots, ", "))
+	}
+
+	return items, nil
+}
+
+// Whether given name designates a single folder below a destination, rather than the destination itself, its parent
+// or a nested path.
+func isValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && name == filepath.Base(name) && name == filepath.Clean(name)
+}
+
+// Whether given remote path exists.
+func exists(path string) (bool, error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to read %s: %w", path, err)
+}
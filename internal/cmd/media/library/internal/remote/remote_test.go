@@ -0,0 +1,198 @@
+package remote
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+func setupServer(t *testing.T) *servicetest.Server {
+	t.Helper()
+
+	server := servicetest.NewServer(t)
+	configtest.Set(t, map[string]any{
+		config.KeySCPDestMoviesPaths:  []string{"/volume1/movies", "/volume2/movies"},
+		config.KeySCPDestTVShowsPaths: []string{"/volume1/tvshows", "/volume2/tvshows"},
+	})
+	require.NoError(t, svc.SFTP.Connect())
+
+	return server
+}
+
+func operations(pl *Plan) []Operation {
+	ops := []Operation{}
+	for _, op := range pl.Operations {
+		ops = append(ops, *op)
+	}
+	return ops
+}
+
+func Test_Find_Returns_Every_Location(t *testing.T) {
+	server := setupServer(t)
+	server.MkdirAll(t, "/volume1/tvshows/Show/Season 1", "/volume2/tvshows/Show/Season 2")
+
+	items, err := Find(media.KindTVShow, "Show")
+	require.NoError(t, err)
+	assert.Equal(t, []*Item{
+		{Root: "/volume1/tvshows", Name: "Show"},
+		{Root: "/volume2/tvshows", Name: "Show"},
+	}, items)
+
+	_, err = Find(media.KindTVShow, "Missing")
+	assert.EqualError(t, err, "could not find Missing in /volume1/tvshows, /volume2/tvshows")
+}
+
+func Test_PlanShowRename_Renames_Seasons_And_Episodes(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.en.srt", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/season 02/Show - S02E01.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Season01.jpg", "")
+
+	items, err := Find(media.KindTVShow, "Show")
+	require.NoError(t, err)
+	pl, err := PlanShowRename(items, "New Show")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []Operation{
+		{ActionRename, "/volume1/tvshows/Show/Season 1/Show - S01E01.en.srt", "/volume1/tvshows/Show/Season 1/New Show - S01E01.en.srt"},
+		{ActionRename, "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv", "/volume1/tvshows/Show/Season 1/New Show - S01E01.mkv"},
+		{ActionRename, "/volume1/tvshows/Show/season 02/Show - S02E01.mkv", "/volume1/tvshows/Show/season 02/New Show - S02E01.mkv"},
+		{ActionRename, "/volume1/tvshows/Show/season 02", "/volume1/tvshows/Show/Season 2"},
+		{ActionRename, "/volume1/tvshows/Show", "/volume1/tvshows/New Show"},
+	}, operations(pl))
+	assert.Equal(t, ActionRename, pl.Operations[len(pl.Operations)-1].Action)
+	assert.Equal(t, "/volume1/tvshows/Show", pl.Operations[len(pl.Operations)-1].From)
+
+	require.NoError(t, pl.Apply(context.Background()))
+
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/New Show/Season 1/New Show - S01E01.mkv"))
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/New Show/Season 1/Season01.jpg"))
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/New Show/Season 2/New Show - S02E01.mkv"))
+	assert.NoDirExists(t, server.LocalPath("/volume1/tvshows/Show"))
+	info, err := os.Stat(server.LocalPath("/volume1/tvshows/New Show/Season 2"))
+	require.NoError(t, err)
+	assert.Equal(t, config.DirectoryMode, info.Mode().Perm())
+}
+
+func Test_PlanMovieRename_Refuses_Existing_Target(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "")
+	server.MkdirAll(t, "/volume1/movies/Other (2020)")
+
+	items, err := Find(media.KindMovie, "Movie (2024)")
+	require.NoError(t, err)
+
+	_, err = PlanMovieRename(items, "Movie")
+	assert.ErrorContains(t, err, `invalid movie name "Movie"`)
+
+	_, err = PlanMovieRename(items, "Other (2020)")
+	assert.EqualError(t, err, "/volume1/movies/Other (2020) already exists")
+
+	pl, err := PlanMovieRename(items, "Movie (2023)")
+	require.NoError(t, err)
+	assert.Equal(t, []Operation{
+		{ActionRename, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "/volume1/movies/Movie (2024)/Movie (2023).mkv"},
+		{ActionRename, "/volume1/movies/Movie (2024)", "/volume1/movies/Movie (2023)"},
+	}, operations(pl))
+}
+
+func Test_PlanMove_Moves_Through_SSH(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "content")
+	server.MkdirAll(t, "/volume2/movies")
+
+	items, err := Find(media.KindMovie, "Movie (2024)")
+	require.NoError(t, err)
+
+	_, err = PlanMove(media.KindMovie, items[0], "/volume3/movies")
+	assert.ErrorContains(t, err, "/volume3/movies is not a configured destination")
+
+	pl, err := PlanMove(media.KindMovie, items[0], "/volume2/movies/")
+	require.NoError(t, err)
+	require.NoError(t, pl.Apply(context.Background()))
+
+	assert.Equal(t, "content", server.ReadFile(t, "/volume2/movies/Movie (2024)/Movie (2024).mkv"))
+	assert.Contains(t, server.Commands(), "mv -n -- '/volume1/movies/Movie (2024)' '/volume2/movies/Movie (2024)'")
+	info, err := os.Stat(server.LocalPath("/volume2/movies/Movie (2024)/Movie (2024).mkv"))
+	require.NoError(t, err)
+	assert.Equal(t, config.FileMode, info.Mode().Perm())
+}
+
+func Test_PlanRemove_Removes_Every_Location(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv", "")
+	server.WriteFile(t, "/volume2/tvshows/Show/Season 2/Show - S02E01.mkv", "")
+
+	items, err := Find(media.KindTVShow, "Show")
+	require.NoError(t, err)
+	require.NoError(t, PlanRemove(items).Apply(context.Background()))
+
+	assert.NoDirExists(t, server.LocalPath("/volume1/tvshows/Show"))
+	assert.NoDirExists(t, server.LocalPath("/volume2/tvshows/Show"))
+}
+
+func Test_Find_Rejects_Names_Outside_Destinations(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "")
+
+	for _, name := range []string{"", ".", "..", "../movies", "Movie (2024)/..", "Movie (2024)/"} {
+		items, err := Find(media.KindMovie, name)
+		assert.ErrorContains(t, err, "invalid name", "name %q", name)
+		assert.Nil(t, items)
+	}
+
+	items, err := Find(media.KindMovie, "Movie (2024)")
+	require.NoError(t, err)
+	for _, name := range []string{".", "..", "../Show"} {
+		_, err = PlanShowRename(items, name)
+		assert.ErrorContains(t, err, "invalid show name", "name %q", name)
+	}
+	_, err = PlanMovieRename(items, "../Other (2024)")
+	assert.ErrorContains(t, err, "invalid movie name")
+
+	assert.FileExists(t, server.LocalPath("/volume1/movies/Movie (2024)/Movie (2024).mkv"))
+}
+
+func Test_FindDuplicates_Compares_Normalized_Names(t *testing.T) {
+	items := []*Item{
+		{Root: "/volume1/movies", Name: "Amélie (2001)"},
+		{Root: "/volume2/movies", Name: "amelie (2001)"},
+		{Root: "/volume1/movies", Name: "Spider-Man (2002)"},
+		{Root: "/volume2/movies", Name: "Spider Man (2002)"},
+		{Root: "/volume2/movies", Name: "Spider-Man (2017)"},
+	}
+
+	duplicates := FindDuplicates(items)
+
+	assert.Equal(t, []*Duplicate{
+		{Key: "amelie (2001)", Items: []*Item{items[0], items[1]}},
+		{Key: "spider man (2002)", Items: []*Item{items[2], items[3]}},
+	}, duplicates)
+}
+
+func Test_MoveToTrash_Keeps_Full_Path(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - 1x01.mkv", "720p")
+	server.WriteFile(t, "/volume1/trash/run/volume1/tvshows/Show/Season 1/Show - 1x02.mkv", "old")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - 1x02.mkv", "720p")
+
+	require.NoError(t, MoveToTrash("/volume1/trash/run", "/volume1/tvshows/Show/Season 1/Show - 1x01.mkv"))
+
+	assert.Equal(t, "720p", server.ReadFile(t, "/volume1/trash/run/volume1/tvshows/Show/Season 1/Show - 1x01.mkv"))
+	_, err := os.Stat(server.LocalPath("/volume1/tvshows/Show/Season 1/Show - 1x01.mkv"))
+	assert.True(t, os.IsNotExist(err))
+
+	err = MoveToTrash("/volume1/trash/run", "/volume1/tvshows/Show/Season 1/Show - 1x02.mkv")
+	assert.ErrorContains(t, err, "already exists")
+	assert.Equal(t, "720p", server.ReadFile(t, "/volume1/tvshows/Show/Season 1/Show - 1x02.mkv"))
+}
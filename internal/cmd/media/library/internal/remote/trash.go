@@ -0,0 +1,47 @@
+package remote
+
+import (
+	"fmt"
+	"path/filepath"
+
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+// Moves given remote files into given trash folder. Their full path is kept below it, so that a file can be restored
+// by hand and files sharing the same name do not collide.
+func MoveToTrash(trash string, paths ...string) error {
+	for _, path := range paths {
+		if err := moveToTrash(trash, path, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Moves given source file into given trash folder, as if it were stored at given path.
+func moveToTrash(trash, source, path string) error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(trash, path)
+
+	found, err := exists(target)
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("failed to trash %s: %s already exists", path, target)
+	}
+	if err := client.MkdirAll(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	// The trash folder usually lives on a different pool, which SFTP renames cannot cross.
+	_, err = svc.SSH.SendCommands(fmt.Sprintf("mv -n -- %s %s", util.ShellQuote(source), util.ShellQuote(target)))
+	if err != nil {
+		return fmt.Errorf("failed to trash %s: %w", path, err)
+	}
+	return nil
+}
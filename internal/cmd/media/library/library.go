@@ -3,8 +3,15 @@ package library
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/artwork"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/audit"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/dupes"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/lint"
 	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/list"
 	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/match"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/mv"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/rename"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/rm"
 	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload"
 	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
 )
@@ -29,8 +36,15 @@ func New() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(artwork.New())
+	cmd.AddCommand(audit.New())
+	cmd.AddCommand(dupes.New())
+	cmd.AddCommand(lint.New())
 	cmd.AddCommand(list.New())
 	cmd.AddCommand(match.New())
+	cmd.AddCommand(mv.New())
+	cmd.AddCommand(rename.New())
+	cmd.AddCommand(rm.New())
 	cmd.AddCommand(upload.New())
 
 	return cmd
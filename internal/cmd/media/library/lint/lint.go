@@ -0,0 +1,267 @@
+package lint
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	lintDesc = "Check remote media against naming conventions"
+	dryRun   bool
+	yes      bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: lintDesc,
+		Long:  lintDesc + " and rename entries that do not follow them.",
+		Args:  cobra.MaximumNArgs(0),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdutil.CallParentPersistentPreRunE(cmd.Parent(), args)
+			if err != nil {
+				return err
+			}
+
+			svc.SSH.SetPrompter(newPrompter())
+			err = svc.SFTP.Connect()
+			if err != nil {
+				return fmt.Errorf("failed to connect to SFTP server: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			options := []string{
+				"movies",
+				"tvshows",
+				"animes",
+			}
+
+			selectedOption, _ := pterm.DefaultInteractiveSelect.
+				WithDefaultText("Select media type").
+				WithOptions(options).
+				Show()
+
+			var subCmd *cobra.Command
+			switch selectedOption {
+			case "movies":
+				subCmd = newMovieCmd()
+
+			case "tvshows":
+				subCmd = newTVShowCmd()
+
+			case "animes":
+				subCmd = newAnimeCmd()
+			}
+
+			fmt.Fprintln(out)
+
+			if err := subCmd.RunE(cmd, args); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print proposed renames without applying them")
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "automatic yes to prompts")
+
+	cmd.AddCommand(newAnimeCmd())
+	cmd.AddCommand(newMovieCmd())
+	cmd.AddCommand(newTVShowCmd())
+
+	return cmd
+}
+
+func newPrompter() prompt.Prompter {
+	if yes {
+		return prompt.NewAuto()
+	}
+	return prompt.NewInteractive()
+}
+
+// Entry that does not follow naming conventions and cannot be renamed automatically.
+type issue struct {
+	path   string
+	reason string
+}
+
+// Renames fixing entries that do not follow naming conventions, along with entries needing manual attention.
+type report struct {
+	plan   *remote.Plan
+	issues []*issue
+}
+
+func (r *report) addIssue(path, format string, args ...any) {
+	r.issues = append(r.issues, &issue{path: path, reason: fmt.Sprintf(format, args...)})
+}
+
+// Adds the rename of given entry, reporting it as an issue when the new name is already taken.
+func (r *report) rename(dir, from, to string) {
+	if err := r.plan.Rename(dir, from, to); err != nil {
+		r.addIssue(filepath.Join(dir, from), "cannot be renamed to %q: %v", to, err)
+	}
+}
+
+// Renames given video file to given name, along with files named after it such as subtitles.
+func (r *report) renameVideo(dir string, entries []fs.FileInfo, video fs.FileInfo, name string) {
+	currentName := strings.TrimSuffix(video.Name(), filepath.Ext(video.Name()))
+	if currentName == name {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(entry.Name(), currentName+"."); ok {
+			r.rename(dir, entry.Name(), name+"."+rest)
+		}
+	}
+}
+
+// Remote media folder along with its entries and the entries of its season folders, if any.
+type folder struct {
+	root    string
+	name    string
+	entries []fs.FileInfo
+	seasons map[string][]fs.FileInfo
+}
+
+func (f *folder) path() string {
+	return filepath.Join(f.root, f.name)
+}
+
+// Reads every media folder of given kind, along with their season folders when asked to.
+func readFolders(ctx context.Context, kind media.Kind, withSeasons bool) ([]*folder, error) {
+	roots, err := remote.Roots(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []*folder
+	for _, root := range roots {
+		entries, err := client.ReadDir(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", root, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				folders = append(folders, &folder{root: root, name: entry.Name(), seasons: map[string][]fs.FileInfo{}})
+			}
+		}
+	}
+
+	eg, _ := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+
+	for _, f := range folders {
+		eg.Go(func() error {
+			entries, err := client.ReadDir(f.path())
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", f.path(), err)
+			}
+			f.entries = entries
+
+			if !withSeasons {
+				return nil
+			}
+			for _, entry := range entries {
+				if _, ok := media.ParseSeasonName(entry.Name()); !ok || !entry.IsDir() {
+					continue
+				}
+				seasonPath := filepath.Join(f.path(), entry.Name())
+				seasonEntries, err := client.ReadDir(seasonPath)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", seasonPath, err)
+				}
+				f.seasons[entry.Name()] = seasonEntries
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(folders, func(a, b *folder) int {
+		return cmp.Compare(a.path(), b.path())
+	})
+
+	return folders, nil
+}
+
+// Checks every media folder of given kind with given function, then prints entries needing manual attention and
+// applies the proposed renames once confirmed.
+func process(
+	ctx context.Context,
+	out io.Writer,
+	p prompt.Prompter,
+	kind media.Kind,
+	lintFolder func(r *report, f *folder),
+) error {
+	spinner, err := pterm.DefaultSpinner.Start("Loading information...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	folders, err := readFolders(ctx, kind, kind != media.KindMovie)
+	if err != nil {
+		return err
+	}
+
+	r := &report{plan: &remote.Plan{}}
+	for _, f := range folders {
+		lintFolder(r, f)
+	}
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	if len(r.issues) > 0 {
+		lw := cmdutil.NewListWriter()
+		for _, i := range r.issues {
+			lw.AppendItem(fmt.Sprintf("%s: %s", i.path, pterm.Red(i.reason)))
+		}
+		pterm.Warning.Printfln("%d entry(ies) need manual attention:", len(r.issues))
+		fmt.Fprintln(out, lw.Render())
+		fmt.Fprintln(out)
+	}
+
+	if err := remote.Run(ctx, out, r.plan, p, dryRun); err != nil {
+		return err
+	}
+
+	if len(r.issues) > 0 {
+		return fmt.Errorf("%d entry(ies) do not follow naming conventions", len(r.issues))
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+func setupServer(t *testing.T) *servicetest.Server {
+	t.Helper()
+
+	server := servicetest.NewServer(t)
+	configtest.Set(t, map[string]any{
+		config.KeySCPDestMoviesPaths:  []string{"/volume1/movies"},
+		config.KeySCPDestTVShowsPaths: []string{"/volume1/tvshows"},
+	})
+	require.NoError(t, svc.SFTP.Connect())
+
+	return server
+}
+
+func Test_Lint_Movies_Renames_Legacy_Names(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/movies/The.Matrix.1999.1080p/the.matrix.1999.1080p.bluray.mkv", "")
+	server.WriteFile(t, "/volume1/movies/The.Matrix.1999.1080p/the.matrix.1999.1080p.bluray.en.srt", "")
+	server.WriteFile(t, "/volume1/movies/The.Matrix.1999.1080p/poster.jpg", "")
+	server.WriteFile(t, "/volume1/movies/Inception (2010)/Inception (2010).mkv", "")
+	server.WriteFile(t, "/volume1/movies/Unknown/first.mkv", "")
+	server.WriteFile(t, "/volume1/movies/Unknown/second.mkv", "")
+
+	out := new(bytes.Buffer)
+	err := process(context.Background(), out, prompt.NewAuto(), media.KindMovie, lintMovie)
+
+	assert.EqualError(t, err, "1 entry(ies) do not follow naming conventions")
+	assert.Contains(t, out.String(), "/volume1/movies/Unknown")
+	assert.FileExists(t, server.LocalPath("/volume1/movies/The Matrix (1999)/The Matrix (1999).mkv"))
+	assert.FileExists(t, server.LocalPath("/volume1/movies/The Matrix (1999)/The Matrix (1999).en.srt"))
+	assert.FileExists(t, server.LocalPath("/volume1/movies/The Matrix (1999)/poster.jpg"))
+	assert.FileExists(t, server.LocalPath("/volume1/movies/Inception (2010)/Inception (2010).mkv"))
+	assert.FileExists(t, server.LocalPath("/volume1/movies/Unknown/first.mkv"))
+}
+
+func Test_Lint_TVShows_Renames_Seasons_And_Episodes(t *testing.T) {
+	server := setupServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/season 01/show.s01e01.720p.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/season 01/show.s01e02.720p.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/season 01/show.s02e01.720p.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 2/Show - S02E02.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 2/Season02.jpg", "")
+
+	out := new(bytes.Buffer)
+	previous := dryRun
+	dryRun = true
+	t.Cleanup(func() { dryRun = previous })
+	err := process(context.Background(), out, prompt.NewAuto(), media.KindTVShow, lintShow)
+
+	assert.EqualError(t, err, "1 entry(ies) do not follow naming conventions")
+	assert.Contains(t, out.String(), "episode belongs to season 2")
+	assert.Contains(t, out.String(), "/volume1/tvshows/Show/season 01/Show - S01E01.mkv")
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/Show/season 01/show.s01e01.720p.mkv"))
+
+	dryRun = false
+	err = process(context.Background(), out, prompt.NewAuto(), media.KindTVShow, lintShow)
+
+	assert.Error(t, err)
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/Show/Season 1/Show - S01E01.mkv"))
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/Show/Season 1/Show - S01E02.mkv"))
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/Show/Season 1/show.s02e01.720p.mkv"))
+	assert.FileExists(t, server.LocalPath("/volume1/tvshows/Show/Season 2/Show - S02E02.mkv"))
+}
@@ -0,0 +1,58 @@
+package lint
+
+import (
+	"io/fs"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+var (
+	movieDesc = "Check movies against the \"<Title> (<Year>)/<Title> (<Year>).<ext>\" convention"
+)
+
+func newMovieCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "movies",
+		Aliases: []string{"movie", "mov", "m"},
+		Short:   movieDesc,
+		Long:    movieDesc + ".",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindMovie, lintMovie)
+		},
+	}
+
+	return cmd
+}
+
+// Checks given movie folder and its video file are named after the movie, guessing title and year from the folder
+// name when it does not follow the convention.
+func lintMovie(r *report, f *folder) {
+	name := f.name
+	if _, _, err := media.ParseMovieName(f.name); err != nil {
+		title, year, err := media.GuessMovie(f.name)
+		if err != nil {
+			r.addIssue(f.path(), "could not guess title and year")
+			return
+		}
+		name = media.MovieName(title, year)
+	}
+
+	videos := lo.Filter(f.entries, func(entry fs.FileInfo, _ int) bool {
+		return !entry.IsDir() && util.IsVideo(entry.Name())
+	})
+	switch len(videos) {
+	case 0:
+		r.addIssue(f.path(), "no video file found")
+	case 1:
+		r.renameVideo(f.path(), f.entries, videos[0], name)
+	default:
+		r.addIssue(f.path(), "%d video files found", len(videos))
+	}
+
+	r.rename(f.root, f.name, name)
+}
@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+var (
+	animeDesc  = "Check animes against the \"<Show>/Season <N>/<Show> - SxxEyy.<ext>\" convention"
+	tvShowDesc = "Check TV shows against the \"<Show>/Season <N>/<Show> - SxxEyy.<ext>\" convention"
+)
+
+func newAnimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "animes",
+		Aliases: []string{"anime", "ani", "a"},
+		Short:   animeDesc,
+		Long:    animeDesc + ".",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindAnime, lintShow)
+		},
+	}
+
+	return cmd
+}
+
+func newTVShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tvshows",
+		Aliases: []string{"tvshow", "tv", "t"},
+		Short:   tvShowDesc,
+		Long:    tvShowDesc + ".",
+		Args:    cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindTVShow, lintShow)
+		},
+	}
+
+	return cmd
+}
+
+// Checks season folders and episodes of given show are named after it. The show folder name is trusted, as it cannot
+// be guessed reliably from episode names.
+func lintShow(r *report, f *folder) {
+	for _, entry := range f.entries {
+		if !entry.IsDir() {
+			if util.IsVideo(entry.Name()) {
+				r.addIssue(filepath.Join(f.path(), entry.Name()), "episode is not in a season folder")
+			}
+			continue
+		}
+
+		seasonNumber, ok := media.ParseSeasonName(entry.Name())
+		if !ok {
+			r.addIssue(filepath.Join(f.path(), entry.Name()), "unexpected folder")
+			continue
+		}
+
+		seasonPath := filepath.Join(f.path(), entry.Name())
+		episodes := f.seasons[entry.Name()]
+		for _, file := range episodes {
+			if file.IsDir() || !util.IsVideo(file.Name()) {
+				continue
+			}
+
+			name, ok := episodeName(f.name, file.Name())
+			if !ok {
+				r.addIssue(filepath.Join(seasonPath, file.Name()), "could not guess season and episode numbers")
+				continue
+			}
+			if name.season != seasonNumber {
+				r.addIssue(filepath.Join(seasonPath, file.Name()), "episode belongs to season %d", name.season)
+				continue
+			}
+			r.renameVideo(seasonPath, episodes, file, name.String())
+		}
+
+		r.rename(f.path(), entry.Name(), media.SeasonName(seasonNumber))
+	}
+}
+
+type episode struct {
+	show    string
+	season  int
+	episode int
+}
+
+func (e *episode) String() string {
+	return media.EpisodeName(e.show, e.season, e.episode)
+}
+
+// Returns the expected name of given episode file of given show, guessing season and episode numbers from its release
+// name when it does not follow the convention.
+func episodeName(showName, basename string) (*episode, bool) {
+	_, seasonNumber, episodeNumber, _, err := media.ParseEpisodeFilename(basename)
+	if err != nil {
+		_, seasonNumber, episodeNumber, err = media.GuessEpisode(basename)
+		if err != nil {
+			return nil, false
+		}
+	}
+	return &episode{show: showName, season: seasonNumber, episode: episodeNumber}, true
+}
@@ -20,6 +20,7 @@ var (
 	listDesc           = "List media files"
 	flagExtended       bool
 	flagOnlyComplete   bool
+	flagOnlyGaps       bool
 	flagOnlyIncomplete bool
 	flagOnlyPartial    bool
 )
@@ -32,7 +33,12 @@ func New() *cobra.Command {
 		Long:    listDesc + ".",
 		Args:    cobra.MaximumNArgs(1),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			err := svc.SFTP.Connect()
+			err := cmdutil.OnlyValidOutputs()
+			if err != nil {
+				return err
+			}
+
+			err = svc.SFTP.Connect()
 			if err != nil {
 				return err
 			}
@@ -80,6 +86,7 @@ func New() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&flagOnlyIncomplete, "only-incomplete", false, "list only incomplete items")
 	cmd.PersistentFlags().BoolVar(&flagOnlyPartial, "only-partial", false, "list only partial items")
 	cmd.MarkFlagsMutuallyExclusive("only-complete", "only-incomplete", "only-partial")
+	cmdutil.AddPersistentOutputFlag(cmd)
 	cmd.AddCommand(newAnimeCmd())
 	cmd.AddCommand(newMovieCmd())
 	cmd.AddCommand(newTVShowCmd())
@@ -88,6 +95,11 @@ func New() *cobra.Command {
 }
 
 func listFolders(ctx context.Context, targets []string) (map[string][]fs.FileInfo, error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
 	mu := sync.Mutex{}
 
 	eg, _ := errgroup.WithContext(ctx)
@@ -96,7 +108,7 @@ func listFolders(ctx context.Context, targets []string) (map[string][]fs.FileInf
 	folders := map[string][]fs.FileInfo{}
 	for _, folder := range targets {
 		eg.Go(func() error {
-			entries, err := svc.SFTP.Client.ReadDir(folder)
+			entries, err := client.ReadDir(folder)
 			if err != nil {
 				return err
 			}
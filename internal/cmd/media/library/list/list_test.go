@@ -0,0 +1,208 @@
+package list
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+func Test_Outputs_Shows_As_JSON(t *testing.T) {
+	testOutputFormat(t, "json")
+
+	shows := []*show{
+		{
+			RemoteDir: "/volume1/tvshows",
+			Name:      "Show",
+			Files:     []string{"background.jpg", "poster.jpg"},
+			Seasons: []*season{
+				{Name: "Season 1", Episodes: []string{"Show - S01E01.mkv"}, Files: []string{"Season01.jpg"}},
+			},
+			State: showStateComplete,
+		},
+	}
+
+	out := new(bytes.Buffer)
+	require.NoError(t, cmdutil.PrintStructured(out, shows))
+	assert.Equal(t, `[
+  {
+    "remoteDir": "/volume1/tvshows",
+    "name": "Show",
+    "seasons": [
+      {
+        "name": "Season 1",
+        "episodes": [
+          "Show - S01E01.mkv"
+        ],
+        "files": [
+          "Season01.jpg"
+        ]
+      }
+    ],
+    "files": [
+      "background.jpg",
+      "poster.jpg"
+    ],
+    "state": "complete"
+  }
+]
+`, out.String())
+}
+
+func Test_Outputs_Movies_As_YAML(t *testing.T) {
+	testOutputFormat(t, "yaml")
+
+	movies := []*movie{
+		{
+			RemoteDir: "/volume1/movies",
+			Name:      "Movie (2024)",
+			Files:     []string{"Movie (2024).mkv", "poster.jpg"},
+			State:     movieStatePartial,
+		},
+	}
+
+	out := new(bytes.Buffer)
+	require.NoError(t, cmdutil.PrintStructured(out, movies))
+	assert.Equal(t, `- remoteDir: /volume1/movies
+  name: Movie (2024)
+  files:
+    - Movie (2024).mkv
+    - poster.jpg
+  state: partial
+`, out.String())
+}
+
+func testOutputFormat(t *testing.T, format string) {
+	t.Helper()
+
+	previous := cmdutil.OutputFormat
+	cmdutil.OutputFormat = format
+	t.Cleanup(func() {
+		cmdutil.OutputFormat = previous
+	})
+}
+
+func Test_Season_Check_Episodes(t *testing.T) {
+	s := &season{
+		Name: "Season 2",
+		Episodes: []string{
+			"Show - S02E01.mkv",
+			"Show - S02E02.mkv",
+			"Show - S02E02.mp4",
+			"Show - S02E05.mkv",
+			"Show - S01E06.mkv",
+			"Other Show - S02E06.mkv",
+			"show.s02e07.mkv",
+		},
+	}
+	s.checkEpisodes("Show")
+
+	assert.Equal(t, []int{3, 4}, s.MissingEpisodes)
+	assert.Equal(t, []int{2}, s.DuplicateEpisodes)
+	assert.Equal(t, []string{"Show - S01E06.mkv", "Other Show - S02E06.mkv", "show.s02e07.mkv"}, s.MisnamedEpisodes)
+
+	sh := &show{Name: "Show", Seasons: []*season{s}}
+	assert.True(t, sh.hasGaps())
+	assert.Equal(t, "missing S02E03, S02E04, duplicate S02E02, 3 misnamed", sh.gapsSummary())
+}
+
+func Test_Season_Check_Episodes_Without_Gaps(t *testing.T) {
+	s := &season{
+		Name:     "Season 1",
+		Episodes: []string{"Show - S01E01.mkv", "Show - S01E02.mkv"},
+	}
+	s.checkEpisodes("Show")
+
+	sh := &show{Name: "Show", Seasons: []*season{s}}
+	assert.False(t, sh.hasGaps())
+	assert.Empty(t, sh.gapsSummary())
+}
+
+func executeList(t *testing.T, args ...string) string {
+	t.Helper()
+
+	testOutputFormat(t, "text")
+
+	cmd := New()
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+
+	return out.String()
+}
+
+func Test_List_Movies_From_Server(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "")
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/background.jpg", "")
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/poster.jpg", "")
+	server.WriteFile(t, "/volume2/movies/Other (2020)/Other (2020).mp4", "")
+	server.WriteFile(t, "/volume2/movies/Other (2020)/poster.jpg", "")
+	configtest.Set(t, map[string]any{config.KeySCPDestMoviesPaths: []string{"/volume1/movies", "/volume2/movies"}})
+
+	output := executeList(t, "movies", "--output", "json")
+
+	assert.JSONEq(t, `[
+  {
+    "remoteDir": "/volume1/movies",
+    "name": "Movie (2024)",
+    "files": ["Movie (2024).mkv", "background.jpg", "poster.jpg"],
+    "state": "complete"
+  },
+  {
+    "remoteDir": "/volume2/movies",
+    "name": "Other (2020)",
+    "files": ["Other (2020).mp4", "poster.jpg"],
+    "state": "partial"
+  }
+]`, output)
+}
+
+func Test_List_TVShows_With_Gaps_From_Server(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E03.mkv", "")
+	server.WriteFile(t, "/volume1/tvshows/Complete/Season 1/Complete - S01E01.mkv", "")
+	configtest.Set(t, map[string]any{config.KeySCPDestTVShowsPaths: []string{"/volume1/tvshows"}})
+
+	output := executeList(t, "tvshows", "--only-gaps", "--output", "json")
+
+	var shows []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &shows))
+	require.Len(t, shows, 1)
+	assert.Equal(t, "Show", shows[0]["name"])
+	assert.Equal(t, "incomplete", shows[0]["state"])
+	seasons := shows[0]["seasons"].([]any)
+	require.Len(t, seasons, 1)
+	assert.Equal(t, []any{float64(2)}, seasons[0].(map[string]any)["missingEpisodes"])
+}
+
+func Test_List_Fails_On_Missing_Remote_Folder(t *testing.T) {
+	servicetest.NewServer(t)
+	configtest.Set(t, map[string]any{config.KeySCPDestMoviesPaths: []string{"/volume1/movies"}})
+	testOutputFormat(t, "text")
+
+	cmd := New()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"movies"})
+
+	assert.ErrorContains(t, cmd.Execute(), "file does not exist")
+}
+
+func Test_List_Movies_Rejects_Only_Gaps(t *testing.T) {
+	cmd := New()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"movies", "--only-gaps"})
+
+	assert.ErrorContains(t, cmd.Execute(), "unknown flag: --only-gaps")
+}
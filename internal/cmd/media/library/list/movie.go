@@ -84,6 +84,11 @@ func processMovies(
 	}
 	defer spinner.Stop()
 
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
 	movies := []*movie{}
 	moviesGroupedByFolder := map[string][]*movie{}
 
@@ -96,7 +101,7 @@ func processMovies(
 				}
 			}
 			m := &movie{
-				sftp:      svc.SFTP.Client,
+				sftp:      client,
 				RemoteDir: destination,
 				Name:      entry.Name(),
 			}
@@ -140,6 +145,12 @@ func processMovies(
 		return fmt.Errorf("could not stop spinner: %w", err)
 	}
 
+	if cmdutil.OutputFormat != "text" {
+		movies := lo.Flatten(lo.Values(moviesGroupedByFolder))
+		sortMovies(movies)
+		return cmdutil.PrintStructured(out, movies)
+	}
+
 	printMovies(out, moviesGroupedByFolder)
 
 	return nil
@@ -198,13 +209,30 @@ const (
 	movieStatePartial               // Missing either "poster.jpg" or "background.jpg".
 )
 
+func (s movieState) String() string {
+	switch s {
+	case movieStateComplete:
+		return "complete"
+	case movieStateIncomplete:
+		return "incomplete"
+	case movieStatePartial:
+		return "partial"
+	default:
+		return "unknown"
+	}
+}
+
+func (s movieState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 type movie struct {
 	sftp *sftp.Client
 
-	RemoteDir string
-	Name      string
-	Files     []string
-	State     movieState
+	RemoteDir string     `json:"remoteDir" yaml:"remoteDir"`
+	Name      string     `json:"name" yaml:"name"`
+	Files     []string   `json:"files" yaml:"files"`
+	State     movieState `json:"state" yaml:"state"`
 }
 
 func (m *movie) loadFiles() error {
@@ -223,7 +251,7 @@ func (m *movie) loadFiles() error {
 
 	for _, movieEntry := range movieEntries {
 		movieEntryName := movieEntry.Name()
-		if slices.Contains(util.AcceptedVideoExtensions, strings.ToLower(filepath.Ext(movieEntryName)[1:])) {
+		if util.IsVideo(movieEntryName) {
 			hasMovieFile = true
 		}
 		if movieEntryName == "background.jpg" {
@@ -20,6 +20,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/media"
 	svc "github.com/jeremiergz/nas-cli/internal/service"
 	"github.com/jeremiergz/nas-cli/internal/util"
 	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
@@ -69,6 +70,9 @@ func newAnimeCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	addOnlyGapsFlag(cmd)
+
 	return cmd
 }
 
@@ -111,9 +115,17 @@ func newTVShowCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	addOnlyGapsFlag(cmd)
+
 	return cmd
 }
 
+// Adds --only-gaps to given shows command, as gaps are only checked for episodes.
+func addOnlyGapsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&flagOnlyGaps, "only-gaps", false, "list only shows with missing, duplicate or misnamed episodes")
+}
+
 func processShows(
 	ctx context.Context,
 	out io.Writer,
@@ -129,6 +141,11 @@ func processShows(
 	}
 	defer spinner.Stop()
 
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
 	shows := []*show{}
 	showsGroupedByFolder := map[string][]*show{}
 
@@ -140,7 +157,7 @@ func processShows(
 				}
 			}
 			s := &show{
-				sftp:      svc.SFTP.Client,
+				sftp:      client,
 				RemoteDir: destination,
 				Name:      entry.Name(),
 			}
@@ -180,10 +197,24 @@ func processShows(
 		}
 	}
 
+	if flagOnlyGaps {
+		for folder, showGroup := range showsGroupedByFolder {
+			showsGroupedByFolder[folder] = lo.Filter(showGroup, func(s *show, _ int) bool {
+				return s.hasGaps()
+			})
+		}
+	}
+
 	if err := spinner.Stop(); err != nil {
 		return fmt.Errorf("could not stop spinner: %w", err)
 	}
 
+	if cmdutil.OutputFormat != "text" {
+		shows := lo.Flatten(lo.Values(showsGroupedByFolder))
+		sortShows(shows)
+		return cmdutil.PrintStructured(out, shows)
+	}
+
 	printShows(out, showsGroupedByFolder)
 
 	return nil
@@ -220,6 +251,10 @@ func printShows(out io.Writer, showsGroupedByFolder map[string][]*show) {
 			showName = show.Name
 		}
 
+		if summary := show.gapsSummary(); summary != "" {
+			showName = fmt.Sprintf("%s  %s", showName, pterm.Yellow(summary))
+		}
+
 		lw.AppendItem(showName)
 		if flagExtended {
 			for _, file := range show.Files {
@@ -237,6 +272,15 @@ func printShows(out io.Writer, showsGroupedByFolder map[string][]*show) {
 				for _, episode := range season.Episodes {
 					lw.AppendItem(episode)
 				}
+				for _, episode := range season.MissingEpisodes {
+					lw.AppendItem(pterm.Red(fmt.Sprintf("missing episode %d", episode)))
+				}
+				for _, episode := range season.DuplicateEpisodes {
+					lw.AppendItem(pterm.Yellow(fmt.Sprintf("duplicate episode %d", episode)))
+				}
+				for _, episode := range season.MisnamedEpisodes {
+					lw.AppendItem(pterm.Yellow(fmt.Sprintf("misnamed episode %s", episode)))
+				}
 				lw.UnIndent()
 				lw.UnIndent()
 			}
@@ -255,21 +299,109 @@ const (
 	showStatePartial              // Missing either "poster.jpg", "background.jpg" or "Season<number>.jpg".
 )
 
+func (s showState) String() string {
+	switch s {
+	case showStateComplete:
+		return "complete"
+	case showStateIncomplete:
+		return "incomplete"
+	case showStatePartial:
+		return "partial"
+	default:
+		return "unknown"
+	}
+}
+
+func (s showState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 type show struct {
 	mu   sync.Mutex
 	sftp *sftp.Client
 
-	RemoteDir string
-	Name      string
-	Seasons   []*season
-	Files     []string
-	State     showState
+	RemoteDir string    `json:"remoteDir" yaml:"remoteDir"`
+	Name      string    `json:"name" yaml:"name"`
+	Seasons   []*season `json:"seasons" yaml:"seasons"`
+	Files     []string  `json:"files" yaml:"files"`
+	State     showState `json:"state" yaml:"state"`
 }
 
 type season struct {
-	Name     string
-	Episodes []string
-	Files    []string
+	Name     string   `json:"name" yaml:"name"`
+	Episodes []string `json:"episodes" yaml:"episodes"`
+	Files    []string `json:"files" yaml:"files"`
+
+	// Episode numbers absent between the first and the last episode of the season.
+	MissingEpisodes []int `json:"missingEpisodes,omitempty" yaml:"missingEpisodes,omitempty"`
+
+	// Episode numbers found in more than one file.
+	DuplicateEpisodes []int `json:"duplicateEpisodes,omitempty" yaml:"duplicateEpisodes,omitempty"`
+
+	// Episode files not following the "<Show> - SxxEyy.<ext>" naming convention.
+	MisnamedEpisodes []string `json:"misnamedEpisodes,omitempty" yaml:"misnamedEpisodes,omitempty"`
+}
+
+// Looks for missing, duplicate and misnamed episodes in the season.
+func (s *season) checkEpisodes(showName string) {
+	seasonNumber, _ := strconv.Atoi(strings.TrimPrefix(s.Name, "Season "))
+
+	counts := map[int]int{}
+	for _, episode := range s.Episodes {
+		name, episodeSeasonNumber, episodeNumber, _, err := media.ParseEpisodeFilename(episode)
+		if err != nil || name != showName || episodeSeasonNumber != seasonNumber {
+			s.MisnamedEpisodes = append(s.MisnamedEpisodes, episode)
+			continue
+		}
+		counts[episodeNumber]++
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	last := slices.Max(lo.Keys(counts))
+	for number := 1; number <= last; number++ {
+		switch count := counts[number]; {
+		case count == 0:
+			s.MissingEpisodes = append(s.MissingEpisodes, number)
+		case count > 1:
+			s.DuplicateEpisodes = append(s.DuplicateEpisodes, number)
+		}
+	}
+}
+
+// Whether any season of the show has missing, duplicate or misnamed episodes.
+func (s *show) hasGaps() bool {
+	return s.gapsSummary() != ""
+}
+
+// Returns a short description of the episode issues found in the show, e.g. "missing S02E07, 1 misnamed".
+func (s *show) gapsSummary() string {
+	var missing, duplicates []string
+	misnamedCount := 0
+	for _, season := range s.Seasons {
+		seasonNumber, _ := strconv.Atoi(strings.TrimPrefix(season.Name, "Season "))
+		for _, episode := range season.MissingEpisodes {
+			missing = append(missing, fmt.Sprintf("S%02dE%02d", seasonNumber, episode))
+		}
+		for _, episode := range season.DuplicateEpisodes {
+			duplicates = append(duplicates, fmt.Sprintf("S%02dE%02d", seasonNumber, episode))
+		}
+		misnamedCount += len(season.MisnamedEpisodes)
+	}
+
+	parts := []string{}
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(missing, ", "))
+	}
+	if len(duplicates) > 0 {
+		parts = append(parts, "duplicate "+strings.Join(duplicates, ", "))
+	}
+	if misnamedCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d misnamed", misnamedCount))
+	}
+
+	return strings.Join(parts, ", ")
 }
 
 func (s *show) loadSeasons() error {
@@ -334,20 +466,23 @@ func (s *show) loadSeasons() error {
 				if isSeasonPoster {
 					seasonFiles = append(seasonFiles, seasonEntryName)
 					hasSeasonPosterImageFile = true
-				} else if isVideoFile(seasonEntryName) {
+				} else if util.IsVideo(seasonEntryName) {
 					episodes = append(episodes, seasonEntry.Name())
 				}
 			}
 
+			showSeason := &season{
+				Name:     showEntry.Name(),
+				Episodes: episodes,
+				Files:    seasonFiles,
+			}
+			showSeason.checkEpisodes(s.Name)
+
 			s.mu.Lock()
 			if !hasSeasonPosterImageFile {
 				hasAllSeasonPosterImageFiles = false
 			}
-			s.Seasons = append(s.Seasons, &season{
-				Name:     showEntry.Name(),
-				Episodes: episodes,
-				Files:    seasonFiles,
-			})
+			s.Seasons = append(s.Seasons, showSeason)
 			s.mu.Unlock()
 			return nil
 		})
@@ -371,14 +506,6 @@ func (s *show) loadSeasons() error {
 	return nil
 }
 
-func isVideoFile(name string) bool {
-	extension := filepath.Ext(name)
-	if len(extension) < 3 {
-		return false
-	}
-	return slices.Contains(util.AcceptedVideoExtensions, strings.ToLower(extension[1:]))
-}
-
 func sortShows(shows []*show) {
 	slices.SortFunc(shows, func(i, j *show) int {
 		return cmp.Compare(
@@ -6,12 +6,15 @@ import (
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
+	"github.com/jeremiergz/nas-cli/internal/prompt"
 	svc "github.com/jeremiergz/nas-cli/internal/service"
 	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
 )
 
 var (
 	matchDesc = "Create Plex matching files to facilitate future scans"
+	dryRun    bool
+	yes       bool
 )
 
 func New() *cobra.Command {
@@ -31,6 +34,15 @@ func New() *cobra.Command {
 				return err
 			}
 
+			err = cmdutil.OnlyValidOutputs()
+			if err != nil {
+				return err
+			}
+			if cmdutil.OutputFormat != "text" && !dryRun && !yes {
+				return fmt.Errorf("--output %s requires either --dry-run or --yes", cmdutil.OutputFormat)
+			}
+
+			svc.SSH.SetPrompter(newPrompter())
 			err = svc.SFTP.Connect()
 			if err != nil {
 				return fmt.Errorf("failed to connect to SFTP server: %w", err)
@@ -74,9 +86,20 @@ func New() *cobra.Command {
 		},
 	}
 
+	cmdutil.AddPersistentOutputFlag(cmd)
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print matching files to write without writing them")
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "automatic yes to prompts")
+
 	cmd.AddCommand(newAnimeCmd())
 	cmd.AddCommand(newMovieCmd())
 	cmd.AddCommand(newTVShowCmd())
 
 	return cmd
 }
+
+func newPrompter() prompt.Prompter {
+	if yes {
+		return prompt.NewAuto()
+	}
+	return prompt.NewInteractive()
+}
@@ -0,0 +1,103 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+var (
+	plexResponses = map[string]string{
+		"/library/sections": `{"MediaContainer":{"Directory":[{"key":"1","title":"Movies","type":"movie"}]}}`,
+		"/library/sections/1/all": `{"MediaContainer":{"Metadata":[
+			{"ratingKey":"10","title":"Movie","year":2024},
+			{"ratingKey":"11","title":"Missing","year":2023}
+		]}}`,
+		"/library/metadata/10": `{"MediaContainer":{"Metadata":[{
+			"Guid":[{"id":"imdb://tt1234567"},{"id":"tmdb://42"}],
+			"Media":[{"Part":[{"file":"/volume1/movies/Movie (2024)/Movie (2024).mkv"}]}]
+		}]}}`,
+		"/library/metadata/11": `{"MediaContainer":{"Metadata":[{
+			"Guid":[{"id":"imdb://tt7654321"}],
+			"Media":[{"Part":[{"file":"/volume1/movies/Missing (2023)/Missing (2023).mkv"}]}]
+		}]}}`,
+	}
+)
+
+// Starts a Plex API stub answering with known responses, closed once the test is done.
+func newPlexServer(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := plexResponses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func Test_Match_Movies_Writes_Matching_Files(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/.plexmatch", "title: Movie\nyear: 2024\nimdbid: tt0000000\n")
+	server.MkdirAll(t, "/volume2/movies/Movie (2024)")
+	configtest.Set(t, map[string]any{
+		config.KeyPlexAPIURL:         newPlexServer(t),
+		config.KeyPlexAPIToken:       "token",
+		config.KeyPlexSectionsMovies: "",
+		config.KeySCPDestMoviesPaths: []string{"/volume1/movies", "/volume2/movies"},
+	})
+	setFlags(t, "json", false, true)
+	require.NoError(t, svc.SFTP.Connect())
+
+	out := new(bytes.Buffer)
+	err := processMovies(context.Background(), out, &mockPrompter{})
+
+	assert.EqualError(t, err, "1 item(s) could not be matched")
+
+	var result struct {
+		Changes []struct {
+			Path   string `json:"path"`
+			Action string `json:"action"`
+		} `json:"changes"`
+		Failures []struct {
+			Name  string `json:"name"`
+			Error string `json:"error"`
+		} `json:"failures"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	require.Len(t, result.Changes, 2)
+	assert.ElementsMatch(t, []string{"/volume1/movies/Movie (2024)", "/volume2/movies/Movie (2024)"}, []string{
+		result.Changes[0].Path,
+		result.Changes[1].Path,
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "Missing (2023)", result.Failures[0].Name)
+
+	assert.Equal(t,
+		"title: Movie\nyear: 2024\nimdbid: tt1234567\ntmdbid: 42\n",
+		server.ReadFile(t, "/volume1/movies/Movie (2024)/.plexmatch"),
+	)
+	assert.Equal(t,
+		"imdbid: tt1234567\ntmdbid: 42\n",
+		server.ReadFile(t, "/volume2/movies/Movie (2024)/.plexmatch"),
+	)
+	info, err := os.Stat(server.LocalPath("/volume2/movies/Movie (2024)/.plexmatch"))
+	require.NoError(t, err)
+	assert.Equal(t, config.FileMode, info.Mode().Perm())
+}
@@ -1,8 +1,19 @@
 package match
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
 	"github.com/pterm/pterm"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
 )
 
 var (
@@ -15,12 +26,87 @@ func newMovieCmd() *cobra.Command {
 		Aliases: []string{"movie", "m"},
 		Short:   movieDesc,
 		Long:    movieDesc + ".",
-		Args:    cobra.MaximumNArgs(1),
+		Args:    cobra.MaximumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			pterm.Info.Println("Media type not yet implemented")
-			return nil
+			return processMovies(cmd.Context(), cmd.OutOrStdout(), newPrompter())
 		},
 	}
 
 	return cmd
 }
+
+func processMovies(ctx context.Context, out io.Writer, p prompt.Prompter) error {
+	spinner, err := pterm.DefaultSpinner.Start("Loading information...")
+	if err != nil {
+		return fmt.Errorf("could not start spinner: %w", err)
+	}
+	defer spinner.Stop()
+
+	movies, err := plex.FetchPlexMovies(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch movies: %w", err)
+	}
+
+	pl := newPlan()
+	eg := errgroup.Group{}
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+	mu := sync.Mutex{}
+	results := make([]*plex.Movie, len(movies))
+
+	for index, movie := range movies {
+		eg.Go(func() error {
+			remoteMovie, err := plex.GetMovieDetails(ctx, movie.Title, movie.Year, movie.RatingKey)
+			if err != nil {
+				pl.addFailure(fmt.Sprintf("%s (%d)", movie.Title, movie.Year), fmt.Errorf("could not get details: %w", err))
+				return nil
+			}
+
+			mu.Lock()
+			results[index] = remoteMovie
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	eg.Wait()
+
+	results = lo.Compact(results)
+	plex.SortMovies(results)
+
+	remoteMovies, err := plex.GetMoviesDetails()
+	if err != nil {
+		return fmt.Errorf("could not list remote movie folders: %w", err)
+	}
+
+	if err := spinner.Stop(); err != nil {
+		return fmt.Errorf("could not stop spinner: %w", err)
+	}
+
+	for _, remoteMovie := range results {
+		name := fmt.Sprintf("%s (%d)", remoteMovie.Name, remoteMovie.Year)
+
+		for _, folderName := range remoteMovie.FolderNames {
+			remoteMovieFolders := remoteMovies[folderName]
+			if len(remoteMovieFolders) == 0 {
+				pl.addFailure(name, fmt.Errorf("could not find remote movie folder %q", folderName))
+				continue
+			}
+
+			for _, remoteMovieDetails := range remoteMovieFolders {
+				pl.addChange(planChange(
+					name,
+					remoteMovie.Description,
+					remoteMovieDetails.Path,
+					remoteMovieDetails.HasMatchingFile,
+					remoteMovieDetails.DBIDs,
+					remoteMovie.IDs,
+					func() error {
+						return plex.WriteMovieMatchingFile(remoteMovie, remoteMovieDetails.Path)
+					},
+				))
+			}
+		}
+	}
+
+	return applyPlan(out, pl, p)
+}
@@ -0,0 +1,251 @@
+package match
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+type action string
+
+const (
+	actionCreate action = "create"
+	actionUpdate action = "update"
+)
+
+// Matching file that would be created or changed to hold the IDs known by Plex.
+type change struct {
+	Name   string     `json:"name" yaml:"name"`
+	Path   string     `json:"path" yaml:"path"`
+	Action action     `json:"action" yaml:"action"`
+	Before []*plex.ID `json:"before" yaml:"before"`
+	After  []*plex.ID `json:"after" yaml:"after"`
+
+	description string
+	write       func() error
+}
+
+// Returns the change needed for the matching file of given folder to hold given IDs, or nil if it already does.
+func planChange(name, description, path string, hasMatchingFile bool, before, after []*plex.ID, write func() error) *change {
+	if slices.EqualFunc(before, after, func(a, b *plex.ID) bool {
+		return a.Identifier == b.Identifier && a.Value == b.Value
+	}) {
+		return nil
+	}
+
+	c := &change{
+		Name:        name,
+		Path:        path,
+		Action:      actionUpdate,
+		Before:      before,
+		After:       after,
+		description: description,
+		write:       write,
+	}
+	if !hasMatchingFile {
+		c.Action = actionCreate
+	}
+	if c.Before == nil {
+		c.Before = []*plex.ID{}
+	}
+
+	return c
+}
+
+// Changes to apply along with items that could not be matched.
+type plan struct {
+	Changes  []*change  `json:"changes" yaml:"changes"`
+	Failures []*failure `json:"failures" yaml:"failures"`
+
+	mu sync.Mutex
+}
+
+// Item that could not be matched, reported once every other item has been processed.
+type failure struct {
+	Name  string `json:"name" yaml:"name"`
+	Error string `json:"error" yaml:"error"`
+}
+
+func newPlan() *plan {
+	return &plan{
+		Changes:  []*change{},
+		Failures: []*failure{},
+	}
+}
+
+func (pl *plan) addChange(c *change) {
+	if c == nil {
+		return
+	}
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.Changes = append(pl.Changes, c)
+}
+
+func (pl *plan) addFailure(name string, err error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.Failures = append(pl.Failures, &failure{Name: name, Error: err.Error()})
+}
+
+// Prints given plan then applies it, asking for confirmation for each change unless --yes is set. Nothing is applied
+// when --dry-run is set, and structured output never prompts. Returns an error if any item could not be matched.
+func applyPlan(out io.Writer, pl *plan, p prompt.Prompter) error {
+	if cmdutil.OutputFormat != "text" {
+		if !dryRun {
+			// Prompts would corrupt machine-readable output, so changes must have been confirmed up front.
+			if !yes {
+				return fmt.Errorf("--output %s requires either --dry-run or --yes", cmdutil.OutputFormat)
+			}
+			writeChanges(pl, p)
+		}
+		if err := cmdutil.PrintStructured(out, pl); err != nil {
+			return err
+		}
+		return failuresError(pl)
+	}
+
+	if len(pl.Changes) == 0 && len(pl.Failures) == 0 {
+		pterm.Success.Println("Nothing to match")
+		return nil
+	}
+
+	if len(pl.Changes) > 0 {
+		printChanges(out, pl.Changes)
+
+		if !dryRun {
+			fmt.Fprintln(out)
+			written := writeChanges(pl, p)
+			pterm.Success.Printfln("Wrote %d matching file(s)", written)
+		}
+	}
+
+	if len(pl.Failures) > 0 {
+		fmt.Fprintln(out)
+		printFailures(out, pl.Failures)
+	}
+
+	return failuresError(pl)
+}
+
+// Writes matching files of given plan, recording failures instead of stopping. Returns the number of written files.
+func writeChanges(pl *plan, p prompt.Prompter) int {
+	written := 0
+	for _, c := range pl.Changes {
+		if !yes {
+			shouldMatch, err := p.Confirm(formatChangePrompt(c), true)
+			if err != nil {
+				break
+			}
+			if !shouldMatch {
+				continue
+			}
+		}
+
+		if err := c.write(); err != nil {
+			pl.addFailure(c.Name, fmt.Errorf("could not write matching file in %q: %w", c.Path, err))
+			continue
+		}
+		written++
+	}
+	return written
+}
+
+func failuresError(pl *plan) error {
+	if len(pl.Failures) > 0 {
+		return fmt.Errorf("%d item(s) could not be matched", len(pl.Failures))
+	}
+	return nil
+}
+
+func printFailures(out io.Writer, failures []*failure) {
+	slices.SortFunc(failures, func(a, b *failure) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	lw := cmdutil.NewListWriter()
+	for _, f := range failures {
+		lw.AppendItem(fmt.Sprintf("%s: %s", f.Name, pterm.Red(f.Error)))
+	}
+
+	pterm.Warning.Printfln("%d item(s) could not be matched:", len(failures))
+	fmt.Fprintln(out, lw.Render())
+}
+
+func printChanges(out io.Writer, changes []*change) {
+	lw := cmdutil.NewListWriter()
+
+	for _, c := range changes {
+		lw.AppendItem(fmt.Sprintf("%s %s %s", c.Name, pterm.Gray("["+c.Path+"]"), pterm.Yellow(string(c.Action))))
+		lw.Indent()
+		for _, identifier := range changedIdentifiers(c) {
+			before := findIDValue(c.Before, identifier)
+			after := findIDValue(c.After, identifier)
+			lw.AppendItem(fmt.Sprintf("%s: %s -> %s",
+				identifier+"id",
+				pterm.Red(cmp.Or(before, "none")),
+				pterm.Green(cmp.Or(after, "none")),
+			))
+		}
+		lw.UnIndent()
+	}
+
+	fmt.Fprintln(out, lw.Render())
+}
+
+// Returns sorted identifiers whose value differs between before and after IDs of given change.
+func changedIdentifiers(c *change) []string {
+	var identifiers []string
+	for _, id := range slices.Concat(c.Before, c.After) {
+		if slices.Contains(identifiers, id.Identifier) {
+			continue
+		}
+		if findIDValue(c.Before, id.Identifier) != findIDValue(c.After, id.Identifier) {
+			identifiers = append(identifiers, id.Identifier)
+		}
+	}
+	slices.Sort(identifiers)
+	return identifiers
+}
+
+func findIDValue(ids []*plex.ID, identifier string) string {
+	for _, id := range ids {
+		if id.Identifier == identifier {
+			return id.Value
+		}
+	}
+	return ""
+}
+
+func formatChangePrompt(c *change) string {
+	var result strings.Builder
+
+	fmt.Fprintf(&result, "Match %s %s:\n",
+		pterm.Blue(c.Name),
+		pterm.Gray("["+c.Path+"]"),
+	)
+
+	if c.description != "" {
+		fmt.Fprintf(&result, "%s\n",
+			pterm.DefaultParagraph.WithMaxWidth(100).Sprint(pterm.Gray(pterm.Italic.Sprint(c.description))),
+		)
+	}
+
+	for _, dbID := range c.After {
+		fmt.Fprintf(&result, " %s: %s\n",
+			pterm.Underscore.Sprint(dbID.Identifier+"id"),
+			dbID.Value,
+		)
+	}
+
+	return result.String()
+}
@@ -0,0 +1,165 @@
+package match
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+type mockPrompter struct {
+	confirmResults []bool
+	confirmIndex   int
+}
+
+func (m *mockPrompter) Confirm(_ string, _ bool) (bool, error) {
+	if m.confirmIndex >= len(m.confirmResults) {
+		return true, nil
+	}
+	r := m.confirmResults[m.confirmIndex]
+	m.confirmIndex++
+	return r, nil
+}
+
+func (m *mockPrompter) Input(_, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
+
+func (m *mockPrompter) Password(label string) (string, error) {
+	return "", fmt.Errorf("unexpected prompt %q", label)
+}
+
+func (m *mockPrompter) Select(_ string, _ []string, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
+
+func setFlags(t *testing.T, outputFormat string, isDryRun, isYes bool) {
+	t.Helper()
+
+	previousOutputFormat, previousDryRun, previousYes := cmdutil.OutputFormat, dryRun, yes
+	cmdutil.OutputFormat, dryRun, yes = outputFormat, isDryRun, isYes
+	t.Cleanup(func() {
+		cmdutil.OutputFormat, dryRun, yes = previousOutputFormat, previousDryRun, previousYes
+	})
+}
+
+func newTestPlan(written *[]string) *plan {
+	ids := []*plex.ID{{Identifier: "tvdb", Value: "353546"}}
+
+	pl := newPlan()
+	pl.Changes = []*change{
+		planChange("Bluey", "", "/tvshows/Bluey", false, nil, ids, func() error {
+			*written = append(*written, "Bluey")
+			return nil
+		}),
+		planChange("Show", "", "/tvshows/Show", true,
+			[]*plex.ID{{Identifier: "imdb", Value: "tt0000000"}},
+			[]*plex.ID{{Identifier: "imdb", Value: "tt1111111"}},
+			func() error {
+				*written = append(*written, "Show")
+				return nil
+			},
+		),
+	}
+	return pl
+}
+
+func Test_PlanChange_Ignores_Matched_Folders(t *testing.T) {
+	ids := []*plex.ID{{Identifier: "tvdb", Value: "353546"}}
+
+	assert.Nil(t, planChange("Bluey", "", "/tvshows/Bluey", true, ids, ids, nil))
+
+	c := planChange("Bluey", "", "/tvshows/Bluey", false, nil, ids, nil)
+	require.NotNil(t, c)
+	assert.Equal(t, actionCreate, c.Action)
+	assert.Equal(t, []*plex.ID{}, c.Before)
+}
+
+func Test_ApplyPlan_Dry_Run_JSON(t *testing.T) {
+	setFlags(t, "json", true, false)
+	var written []string
+	out := new(bytes.Buffer)
+
+	err := applyPlan(out, newTestPlan(&written), &mockPrompter{})
+
+	require.NoError(t, err)
+	assert.Empty(t, written)
+	assert.JSONEq(t, `{
+		"changes": [
+			{"name": "Bluey", "path": "/tvshows/Bluey", "action": "create", "before": [], "after": [{"identifier": "tvdb", "value": "353546"}]},
+			{"name": "Show", "path": "/tvshows/Show", "action": "update", "before": [{"identifier": "imdb", "value": "tt0000000"}], "after": [{"identifier": "imdb", "value": "tt1111111"}]}
+		],
+		"failures": []
+	}`, out.String())
+}
+
+func Test_ApplyPlan_JSON_Requires_Yes(t *testing.T) {
+	setFlags(t, "json", false, false)
+	var written []string
+	out := new(bytes.Buffer)
+	p := &mockPrompter{}
+
+	err := applyPlan(out, newTestPlan(&written), p)
+
+	assert.ErrorContains(t, err, "--output json requires either --dry-run or --yes")
+	assert.Zero(t, p.confirmIndex)
+	assert.Empty(t, written)
+	assert.Empty(t, out.String())
+}
+
+func Test_ApplyPlan_Prompts_For_Each_Change(t *testing.T) {
+	setFlags(t, "text", false, false)
+	var written []string
+
+	err := applyPlan(new(bytes.Buffer), newTestPlan(&written), &mockPrompter{confirmResults: []bool{false, true}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Show"}, written)
+}
+
+func Test_ApplyPlan_With_Yes(t *testing.T) {
+	setFlags(t, "text", false, true)
+	var written []string
+	out := new(bytes.Buffer)
+
+	err := applyPlan(out, newTestPlan(&written), &mockPrompter{confirmResults: []bool{false, false}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bluey", "Show"}, written)
+	assert.Contains(t, out.String(), "tt0000000")
+	assert.Contains(t, out.String(), "tt1111111")
+}
+
+func Test_ApplyPlan_Reports_Failures(t *testing.T) {
+	setFlags(t, "json", false, true)
+	var written []string
+	out := new(bytes.Buffer)
+
+	pl := newTestPlan(&written)
+	pl.Changes[0].write = func() error { return errors.New("permission denied") }
+	pl.addFailure("Split Show", errors.New(`could not find remote TV show folder "Split Show"`))
+
+	err := applyPlan(out, pl, &mockPrompter{})
+
+	assert.EqualError(t, err, "2 item(s) could not be matched")
+	assert.Equal(t, []string{"Show"}, written)
+	assert.JSONEq(t, `[
+		{"name": "Split Show", "error": "could not find remote TV show folder \"Split Show\""},
+		{"name": "Bluey", "error": "could not write matching file in \"/tvshows/Bluey\": permission denied"}
+	]`, mustMarshal(t, pl.Failures))
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+
+	content, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(content)
+}
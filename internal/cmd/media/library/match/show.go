@@ -3,15 +3,17 @@ package match
 import (
 	"context"
 	"fmt"
-	"slices"
-	"strings"
+	"io"
 	"sync"
 
 	"github.com/pterm/pterm"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 
-	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/match/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/plex"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
 )
 
 var (
@@ -27,7 +29,7 @@ func newAnimeCmd() *cobra.Command {
 		Long:    animeDesc + ".",
 		Args:    cobra.MaximumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return processShows(cmd.Context(), plex.ShowsKindAnime)
+			return processShows(cmd.Context(), cmd.OutOrStdout(), plex.ShowsKindAnime, newPrompter())
 		},
 	}
 	return cmd
@@ -41,34 +43,37 @@ func newTVShowCmd() *cobra.Command {
 		Long:    tvShowDesc + ".",
 		Args:    cobra.MaximumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return processShows(cmd.Context(), plex.ShowsKindTVShow)
+			return processShows(cmd.Context(), cmd.OutOrStdout(), plex.ShowsKindTVShow, newPrompter())
 		},
 	}
 
 	return cmd
 }
 
-func processShows(_ context.Context, kind plex.ShowsKind) error {
+func processShows(ctx context.Context, out io.Writer, kind plex.ShowsKind, p prompt.Prompter) error {
 	spinner, err := pterm.DefaultSpinner.Start("Loading information...")
 	if err != nil {
 		return fmt.Errorf("could not start spinner: %w", err)
 	}
 	defer spinner.Stop()
 
-	shows, err := plex.FetchPlexShows(kind)
+	shows, err := plex.FetchPlexShows(ctx, kind)
 	if err != nil {
 		return fmt.Errorf("could not fetch %ss: %w", kind.DisplayText(), err)
 	}
 
+	pl := newPlan()
 	eg := errgroup.Group{}
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
 	mu := sync.Mutex{}
 	results := make([]*plex.Show, len(shows))
 
 	for index, show := range shows {
 		eg.Go(func() error {
-			remoteShow, err := plex.GetShowDetails(show.Title, show.RatingKey)
+			remoteShow, err := plex.GetShowDetails(ctx, show.Title, show.RatingKey)
 			if err != nil {
-				return fmt.Errorf("could not get details for %q: %w", show.Title, err)
+				pl.addFailure(show.Title, fmt.Errorf("could not get details: %w", err))
+				return nil
 			}
 
 			mu.Lock()
@@ -78,10 +83,9 @@ func processShows(_ context.Context, kind plex.ShowsKind) error {
 			return nil
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return fmt.Errorf("could not match %ss: %w", kind.DisplayText(), err)
-	}
+	eg.Wait()
 
+	results = lo.Compact(results)
 	plex.SortShows(results)
 
 	remoteShows, err := plex.GetShowsDetails(kind)
@@ -93,60 +97,30 @@ func processShows(_ context.Context, kind plex.ShowsKind) error {
 		return fmt.Errorf("could not stop spinner: %w", err)
 	}
 
-	hasMatchedAny := false
-
 	for _, remoteShow := range results {
-		remoteShowDetails := remoteShows[remoteShow.FolderName]
-		if remoteShowDetails == nil {
-			return fmt.Errorf("could not find remote %s folder for %q", kind.DisplayText(), remoteShow.FolderName)
-		}
-
-		// Ignore if already fully matched.
-		if slices.EqualFunc(remoteShowDetails.DBIDs, remoteShow.IDs, func(a, b *plex.ID) bool {
-			return a.Identifier == b.Identifier && a.Value == b.Value
-		}) {
-			continue
-		}
+		// Shows may be split across several locations, each of them needing its own matching file.
+		for _, folderName := range remoteShow.FolderNames {
+			remoteShowFolders := remoteShows[folderName]
+			if len(remoteShowFolders) == 0 {
+				pl.addFailure(remoteShow.Name, fmt.Errorf("could not find remote %s folder %q", kind.DisplayText(), folderName))
+				continue
+			}
 
-		shouldMatch, _ := pterm.DefaultInteractiveConfirm.
-			WithDefaultText(formatShowTextPrompt(remoteShow, remoteShowDetails.Path)).
-			WithDefaultValue(true).
-			Show()
-		if shouldMatch {
-			if err := plex.WriteShowMatchingFile(remoteShow, remoteShowDetails.Path); err != nil {
-				return fmt.Errorf("could not write matching file for %q: %w", remoteShow.Name, err)
+			for _, remoteShowDetails := range remoteShowFolders {
+				pl.addChange(planChange(
+					remoteShow.Name,
+					remoteShow.Description,
+					remoteShowDetails.Path,
+					remoteShowDetails.HasMatchingFile,
+					remoteShowDetails.DBIDs,
+					remoteShow.IDs,
+					func() error {
+						return plex.WriteShowMatchingFile(remoteShow, remoteShowDetails.Path)
+					},
+				))
 			}
-			hasMatchedAny = true
 		}
 	}
 
-	if !hasMatchedAny {
-		pterm.Success.Println("Nothing to match")
-	}
-
-	return nil
-}
-
-func formatShowTextPrompt(show *plex.Show, showPath string) string {
-	var result strings.Builder
-
-	fmt.Fprintf(&result, "Match %s %s:\n",
-		pterm.Blue(show.Name),
-		pterm.Gray("["+showPath+"]"),
-	)
-
-	if show.Description != "" {
-		fmt.Fprintf(&result, "%s\n",
-			pterm.DefaultParagraph.WithMaxWidth(100).Sprint(pterm.Gray(pterm.Italic.Sprint(show.Description))),
-		)
-	}
-
-	for _, dbID := range show.IDs {
-		fmt.Fprintf(&result, " %s: %s\n",
-			pterm.Underscore.Sprint(dbID.Identifier+"id"),
-			dbID.Value,
-		)
-	}
-
-	return result.String()
+	return applyPlan(out, pl, p)
 }
@@ -0,0 +1,161 @@
+package mv
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	mvDesc     = "Move remote media to another destination"
+	animeDesc  = "Move an anime"
+	movieDesc  = "Move a movie"
+	tvShowDesc = "Move a TV show"
+	dryRun     bool
+	yes        bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mv",
+		Short: mvDesc,
+		Long:  mvDesc + " configured for the same kind of media.",
+		Args:  cobra.ExactArgs(2),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdutil.CallParentPersistentPreRunE(cmd.Parent(), args)
+			if err != nil {
+				return err
+			}
+
+			svc.SSH.SetPrompter(newPrompter())
+			err = svc.SFTP.Connect()
+			if err != nil {
+				return fmt.Errorf("failed to connect to SFTP server: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			options := []string{
+				"movies",
+				"tvshows",
+				"animes",
+			}
+
+			selectedOption, _ := pterm.DefaultInteractiveSelect.
+				WithDefaultText("Select media type").
+				WithOptions(options).
+				Show()
+
+			var subCmd *cobra.Command
+			switch selectedOption {
+			case "movies":
+				subCmd = newMovieCmd()
+
+			case "tvshows":
+				subCmd = newTVShowCmd()
+
+			case "animes":
+				subCmd = newAnimeCmd()
+			}
+
+			fmt.Fprintln(out)
+
+			if err := subCmd.RunE(cmd, args); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print changes without applying them")
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "automatic yes to prompts")
+
+	cmd.AddCommand(newAnimeCmd())
+	cmd.AddCommand(newMovieCmd())
+	cmd.AddCommand(newTVShowCmd())
+
+	return cmd
+}
+
+func newAnimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "animes <name> <destination>",
+		Aliases: []string{"anime", "ani", "a"},
+		Short:   animeDesc,
+		Long:    animeDesc + ".",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindAnime, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func newMovieCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "movies <name> <destination>",
+		Aliases: []string{"movie", "mov", "m"},
+		Short:   movieDesc,
+		Long:    movieDesc + ".",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindMovie, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func newTVShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tvshows <name> <destination>",
+		Aliases: []string{"tvshow", "tv", "t"},
+		Short:   tvShowDesc,
+		Long:    tvShowDesc + ".",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return process(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindTVShow, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func process(ctx context.Context, out io.Writer, p prompt.Prompter, kind media.Kind, name, destination string) error {
+	items, err := remote.Find(kind, name)
+	if err != nil {
+		return err
+	}
+	if len(items) > 1 {
+		// Merging locations of a split item is not supported, as files could collide.
+		return fmt.Errorf("%s is split across %d destinations", name, len(items))
+	}
+
+	pl, err := remote.PlanMove(kind, items[0], destination)
+	if err != nil {
+		return err
+	}
+
+	return remote.Run(ctx, out, pl, p, dryRun)
+}
+
+func newPrompter() prompt.Prompter {
+	if yes {
+		return prompt.NewAuto()
+	}
+	return prompt.NewInteractive()
+}
@@ -0,0 +1,45 @@
+package rename
+
+import (
+	"context"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+)
+
+var (
+	movieDesc = "Rename a movie"
+)
+
+func newMovieCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "movies <name> <new-name>",
+		Aliases: []string{"movie", "mov", "m"},
+		Short:   movieDesc,
+		Long:    movieDesc + ", expecting its new name to follow the \"<Title> (<Year>)\" convention.",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return processMovie(cmd.Context(), cmd.OutOrStdout(), newPrompter(), args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func processMovie(ctx context.Context, out io.Writer, p prompt.Prompter, name, newName string) error {
+	items, err := remote.Find(media.KindMovie, name)
+	if err != nil {
+		return err
+	}
+
+	pl, err := remote.PlanMovieRename(items, newName)
+	if err != nil {
+		return err
+	}
+
+	return remote.Run(ctx, out, pl, p, dryRun)
+}
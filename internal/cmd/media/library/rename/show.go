@@ -0,0 +1,61 @@
+package rename
+
+import (
+	"context"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+)
+
+var (
+	animeDesc  = "Rename an anime"
+	tvShowDesc = "Rename a TV show"
+)
+
+func newAnimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "animes <name> <new-name>",
+		Aliases: []string{"anime", "ani", "a"},
+		Short:   animeDesc,
+		Long:    animeDesc + " along with its seasons and episodes.",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return processShow(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindAnime, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func newTVShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tvshows <name> <new-name>",
+		Aliases: []string{"tvshow", "tv", "t"},
+		Short:   tvShowDesc,
+		Long:    tvShowDesc + " along with its seasons and episodes.",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return processShow(cmd.Context(), cmd.OutOrStdout(), newPrompter(), media.KindTVShow, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func processShow(ctx context.Context, out io.Writer, p prompt.Prompter, kind media.Kind, name, newName string) error {
+	items, err := remote.Find(kind, name)
+	if err != nil {
+		return err
+	}
+
+	pl, err := remote.PlanShowRename(items, newName)
+	if err != nil {
+		return err
+	}
+
+	return remote.Run(ctx, out, pl, p, dryRun)
+}
@@ -0,0 +1,188 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/probe"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+const (
+	existingMovieKeepBoth = "keep both"
+	existingMovieSkip     = "skip"
+)
+
+// Copy of a movie already stored remotely, along with its video files.
+type existingMovie struct {
+	item   *remote.Item
+	videos []*movieVideo
+}
+
+// Returns the path given movie is uploaded to when replacing this copy. The existing folder name is kept, so that
+// the video file is named after it.
+func (e *existingMovie) destination(movie *media.Movie) string {
+	return filepath.Join(e.item.Path(), fmt.Sprintf("%s.%s", e.item.Name, movie.Extension()))
+}
+
+// Returns the video files of this copy, removed once replaced.
+func (e *existingMovie) replacedFiles() []string {
+	return lo.Map(e.videos, func(video *movieVideo, _ int) string { return video.path })
+}
+
+// Video file of a movie, described to compare a new release with an existing one. Details are nil when the file could
+// not be probed.
+type movieVideo struct {
+	path    string
+	size    int64
+	details *probe.Details
+}
+
+// Returns remote folders holding given movies, grouped by movie folder name. Names are compared regardless of case,
+// diacritics and punctuation, so that movies uploaded under a slightly different name are found as well.
+func findExistingMovies(movies []*media.Movie) (map[string][]*remote.Item, error) {
+	items, err := remote.List(media.KindMovie)
+	if err != nil {
+		return nil, fmt.Errorf("could not list remote movies: %w", err)
+	}
+	itemsByName := lo.GroupBy(items, func(item *remote.Item) string {
+		return remote.NormalizeName(item.Name)
+	})
+
+	existing := map[string][]*remote.Item{}
+	for _, movie := range movies {
+		if found := itemsByName[remote.NormalizeName(movieDirname(movie))]; len(found) > 0 {
+			existing[movieDirname(movie)] = found
+		}
+	}
+
+	return existing, nil
+}
+
+// Compares given movie with its remote copies and asks whether to replace one of them, keep both or skip the upload.
+// Replacing is only suggested by default when the new release is better than every existing one.
+func resolveExistingMovie(
+	ctx context.Context,
+	out io.Writer,
+	p prompt.Prompter,
+	movie *media.Movie,
+	items []*remote.Item,
+) (toReplace *existingMovie, skip bool, err error) {
+	newVideo := localMovieVideo(ctx, movie.FilePath())
+
+	existing := make([]*existingMovie, len(items))
+	for i, item := range items {
+		videos, err := remoteMovieVideos(item.Path())
+		if err != nil {
+			return nil, false, err
+		}
+		existing[i] = &existingMovie{item: item, videos: videos}
+	}
+
+	var rows [][]string
+	for _, e := range existing {
+		for _, video := range e.videos {
+			rows = append(rows, upgradeRow(pterm.Red("old"), video.path, video.details, video.size))
+		}
+	}
+	rows = append(rows, upgradeRow(pterm.Green("new"), movie.Basename(), newVideo.details, newVideo.size))
+
+	table, err := renderComparison(rows)
+	if err != nil {
+		return nil, false, err
+	}
+	pterm.Warning.Printfln("%s already exists", movieDirname(movie))
+	fmt.Fprintln(out, table)
+
+	options := make([]string, 0, len(existing)+2)
+	for _, e := range existing {
+		options = append(options, replaceOption(e))
+	}
+	options = append(options, existingMovieKeepBoth, existingMovieSkip)
+
+	defaultOption := existingMovieSkip
+	if isBetterThanAll(newVideo, existing) {
+		defaultOption = options[0]
+	}
+
+	selected, err := p.Select("What to do?", options, defaultOption)
+	if err != nil {
+		return nil, false, err
+	}
+	fmt.Fprintln(out)
+
+	switch selected {
+	case existingMovieKeepBoth:
+		return nil, false, nil
+	case existingMovieSkip:
+		return nil, true, nil
+	}
+	for _, e := range existing {
+		if selected == replaceOption(e) {
+			return e, false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("unexpected choice %q", selected)
+}
+
+func replaceOption(e *existingMovie) string {
+	return "replace " + e.item.Path()
+}
+
+// Whether given video is a better release than every video of given copies.
+func isBetterThanAll(video *movieVideo, existing []*existingMovie) bool {
+	for _, e := range existing {
+		for _, v := range e.videos {
+			if !isBetterRelease(video.details, v.details) {
+				return false
+			}
+		}
+	}
+	return video.details != nil
+}
+
+func localMovieVideo(ctx context.Context, path string) *movieVideo {
+	video := &movieVideo{path: path}
+	if info, err := os.Stat(path); err == nil {
+		video.size = info.Size()
+	}
+	video.details, _ = probeLocal(ctx, path)
+
+	return video
+}
+
+// Returns video files of given remote movie folder, probed on the server so that files are not downloaded.
+func remoteMovieVideos(dir string) ([]*movieVideo, error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := client.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var videos []*movieVideo
+	for _, entry := range entries {
+		if entry.IsDir() || !util.IsVideo(entry.Name()) {
+			continue
+		}
+
+		video := &movieVideo{path: filepath.Join(dir, entry.Name()), size: entry.Size()}
+		video.details, _ = probeRemote(video.path)
+		videos = append(videos, video)
+	}
+
+	return videos, nil
+}
@@ -0,0 +1,232 @@
+package diskusage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"github.com/samber/lo"
+
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+const (
+	ProviderDF      string = "df"
+	ProviderStatVFS string = "statvfs"
+	ProviderZFS     string = "zfs"
+)
+
+var (
+	// All supported provider names.
+	Providers = []string{ProviderDF, ProviderStatVFS, ProviderZFS}
+)
+
+// Holds storage usage information for a remote path.
+type Usage struct {
+	Path  string
+	Total uint64
+	Used  uint64
+	Free  uint64
+
+	// Identifies the file system holding the path, such as its mount point or pool name. Paths on the same file
+	// system share its free space.
+	Filesystem string
+}
+
+// Returns the used space as a percentage of the total space.
+func (u *Usage) Percentage() int {
+	if u.Total == 0 {
+		return 100
+	}
+	return int(u.Used * 100 / u.Total)
+}
+
+// Reports storage usage of remote paths.
+type Provider interface {
+	Usage(paths []string) (map[string]*Usage, error)
+}
+
+// Runs given commands on the remote server and returns their output.
+type CommandRunner func(cmds ...string) ([]byte, error)
+
+// Subset of the SFTP client used to query file system statistics.
+type StatVFSClient interface {
+	StatVFS(path string) (*sftp.StatVFS, error)
+}
+
+// Returns the provider matching given name.
+func New(name string, run CommandRunner, client StatVFSClient) (Provider, error) {
+	switch name {
+	case ProviderDF:
+		return NewDF(run), nil
+	case ProviderStatVFS:
+		return NewStatVFS(client), nil
+	case ProviderZFS:
+		return NewZFS(run), nil
+	default:
+		return nil, fmt.Errorf("unsupported disk usage provider %q, expected one of %s", name, strings.Join(Providers, ", "))
+	}
+}
+
+type zfsProvider struct {
+	run CommandRunner
+}
+
+// Returns a provider reading ZFS pools usage with "zpool list". Paths are associated with the pool whose name they
+// contain, preferring the longest pool name when several match.
+func NewZFS(run CommandRunner) Provider {
+	return &zfsProvider{run: run}
+}
+
+func (p *zfsProvider) Usage(paths []string) (map[string]*Usage, error) {
+	raw, err := p.run("zpool list -Hp -o name,size,alloc,free")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get remote disk usage: %w", err)
+	}
+
+	pools := map[string]*Usage{}
+	for line := range strings.Lines(string(raw)) {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		values, err := parseUints(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse zpool output %q: %w", strings.TrimSpace(line), err)
+		}
+		pools[fields[0]] = &Usage{Total: values[0], Used: values[1], Free: values[2], Filesystem: fields[0]}
+	}
+
+	usages := make(map[string]*Usage, len(paths))
+	for _, path := range paths {
+		var pool string
+		for name := range pools {
+			if strings.Contains(path, name) && len(name) > len(pool) {
+				pool = name
+			}
+		}
+		if pool == "" {
+			return nil, fmt.Errorf("could not find pool for path %s", path)
+		}
+		usage := *pools[pool]
+		usage.Path = path
+		usages[path] = &usage
+	}
+
+	return usages, nil
+}
+
+type dfProvider struct {
+	run CommandRunner
+}
+
+// Returns a provider reading file systems usage with POSIX "df".
+func NewDF(run CommandRunner) Provider {
+	return &dfProvider{run: run}
+}
+
+func (p *dfProvider) Usage(paths []string) (map[string]*Usage, error) {
+	if len(paths) == 0 {
+		return map[string]*Usage{}, nil
+	}
+
+	args := make([]string, len(paths))
+	for i, path := range paths {
+		args[i] = util.ShellQuote(path)
+	}
+	raw, err := p.run("df -P -k " + strings.Join(args, " "))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get remote disk usage: %w", err)
+	}
+
+	// Skip the header, then expect one line per path in the order they were given.
+	var lines []string
+	for line := range strings.Lines(string(raw)) {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != len(paths)+1 {
+		return nil, fmt.Errorf("unexpected df output: got %d lines for %d paths", len(lines)-1, len(paths))
+	}
+
+	usages := make(map[string]*Usage, len(paths))
+	for i, line := range lines[1:] {
+		values, mount, err := parseDFLine(line)
+		if err != nil {
+			return nil, err
+		}
+		usages[paths[i]] = &Usage{
+			Path:       paths[i],
+			Total:      values[0] * 1024,
+			Used:       values[1] * 1024,
+			Free:       values[2] * 1024,
+			Filesystem: mount,
+		}
+	}
+
+	return usages, nil
+}
+
+// Parses a line of "df -P" output. Both file system names and mount points may contain spaces, so values are found
+// from the capacity column, which is the first one made of a percentage following three numbers. The mount point is
+// made of every field after it.
+func parseDFLine(line string) (values []uint64, mount string, err error) {
+	fields := strings.Fields(line)
+	for i := 4; i < len(fields)-1; i++ {
+		if !strings.HasSuffix(fields[i], "%") {
+			continue
+		}
+		if _, err := strconv.ParseUint(strings.TrimSuffix(fields[i], "%"), 10, 64); err != nil {
+			continue
+		}
+		values, err := parseUints(fields[i-3 : i])
+		if err != nil {
+			continue
+		}
+		return values, strings.Join(fields[i+1:], " "), nil
+	}
+	return nil, "", fmt.Errorf("could not parse df output %q", strings.TrimSpace(line))
+}
+
+type statVFSProvider struct {
+	client StatVFSClient
+}
+
+// Returns a provider using the "statvfs@openssh.com" SFTP extension. Paths are associated with the file system ID
+// reported by the server, or considered on their own file system when it does not report any.
+func NewStatVFS(client StatVFSClient) Provider {
+	return &statVFSProvider{client: client}
+}
+
+func (p *statVFSProvider) Usage(paths []string) (map[string]*Usage, error) {
+	usages := make(map[string]*Usage, len(paths))
+	for _, path := range paths {
+		stats, err := p.client.StatVFS(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get remote disk usage for %s: %w", path, err)
+		}
+		total := stats.TotalSpace()
+		usages[path] = &Usage{
+			Path:       path,
+			Total:      total,
+			Used:       total - stats.FreeSpace(),
+			Free:       stats.Bavail * stats.Frsize,
+			Filesystem: lo.Ternary(stats.Fsid != 0, strconv.FormatUint(stats.Fsid, 10), path),
+		}
+	}
+	return usages, nil
+}
+
+func parseUints(fields []string) ([]uint64, error) {
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,98 @@
+package diskusage
+
+import (
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeRunner(output string) CommandRunner {
+	return func(cmds ...string) ([]byte, error) {
+		return []byte(output), nil
+	}
+}
+
+func Test_ZFS_Usage(t *testing.T) {
+	provider := NewZFS(func(cmds ...string) ([]byte, error) {
+		assert.Equal(t, []string{"zpool list -Hp -o name,size,alloc,free"}, cmds)
+		return []byte("tank\t1000\t250\t750\r\ntank-media\t2000\t1500\t500\r\n"), nil
+	})
+
+	usages, err := provider.Usage([]string{"/mnt/tank/movies", "/mnt/tank-media/movies"})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Usage{Path: "/mnt/tank/movies", Total: 1000, Used: 250, Free: 750, Filesystem: "tank"}, usages["/mnt/tank/movies"])
+	assert.Equal(t, &Usage{Path: "/mnt/tank-media/movies", Total: 2000, Used: 1500, Free: 500, Filesystem: "tank-media"}, usages["/mnt/tank-media/movies"])
+	assert.Equal(t, 75, usages["/mnt/tank-media/movies"].Percentage())
+}
+
+func Test_ZFS_Usage_Unknown_Pool(t *testing.T) {
+	provider := NewZFS(fakeRunner("tank\t1000\t250\t750\n"))
+
+	_, err := provider.Usage([]string{"/volume1/movies"})
+	assert.ErrorContains(t, err, "could not find pool for path /volume1/movies")
+}
+
+func Test_DF_Usage(t *testing.T) {
+	provider := NewDF(func(cmds ...string) ([]byte, error) {
+		assert.Equal(t, []string{"df -P -k '/volume1/movies' '/volume2/tv shows'"}, cmds)
+		return []byte("Filesystem     1024-blocks      Used Available Capacity Mounted on\r\n" +
+			"/dev/md0              1000       400       600      40% /volume1\r\n" +
+			"my volume             2000      1000      1000      50% /volume2\r\n"), nil
+	})
+
+	usages, err := provider.Usage([]string{"/volume1/movies", "/volume2/tv shows"})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Usage{Path: "/volume1/movies", Total: 1000 * 1024, Used: 400 * 1024, Free: 600 * 1024, Filesystem: "/volume1"}, usages["/volume1/movies"])
+	assert.Equal(t, &Usage{Path: "/volume2/tv shows", Total: 2000 * 1024, Used: 1000 * 1024, Free: 1000 * 1024, Filesystem: "/volume2"}, usages["/volume2/tv shows"])
+}
+
+func Test_DF_Usage_Mount_Point_With_Spaces(t *testing.T) {
+	provider := NewDF(fakeRunner("Filesystem     1024-blocks      Used Available Capacity Mounted on\n" +
+		"//nas/media 2000 1000 1000 50% /mnt/media share 2024\n"))
+
+	usages, err := provider.Usage([]string{"/mnt/media share 2024/movies"})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Usage{Path: "/mnt/media share 2024/movies", Total: 2000 * 1024, Used: 1000 * 1024, Free: 1000 * 1024, Filesystem: "/mnt/media share 2024"}, usages["/mnt/media share 2024/movies"])
+
+	values, mount, err := parseDFLine("my volume 2 2000 1000 1000 50% /mnt/media share 2024")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2000, 1000, 1000}, values)
+	assert.Equal(t, "/mnt/media share 2024", mount)
+}
+
+func Test_DF_Usage_Unexpected_Output(t *testing.T) {
+	provider := NewDF(fakeRunner("df: /missing: No such file or directory\r\n"))
+
+	_, err := provider.Usage([]string{"/missing"})
+	assert.Error(t, err)
+}
+
+type fakeStatVFSClient map[string]*sftp.StatVFS
+
+func (c fakeStatVFSClient) StatVFS(path string) (*sftp.StatVFS, error) {
+	return c[path], nil
+}
+
+func Test_StatVFS_Usage(t *testing.T) {
+	provider := NewStatVFS(fakeStatVFSClient{
+		"/data/movies":  {Frsize: 4096, Blocks: 100, Bfree: 40, Bavail: 30, Fsid: 42},
+		"/data/tvshows": {Frsize: 4096, Blocks: 100, Bfree: 40, Bavail: 30},
+	})
+
+	usages, err := provider.Usage([]string{"/data/movies", "/data/tvshows"})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Usage{Path: "/data/movies", Total: 409600, Used: 245760, Free: 122880, Filesystem: "42"}, usages["/data/movies"])
+	assert.Equal(t, 60, usages["/data/movies"].Percentage())
+	assert.Equal(t, "/data/tvshows", usages["/data/tvshows"].Filesystem)
+}
+
+func Test_New_Unsupported_Provider(t *testing.T) {
+	_, err := New("btrfs", nil, nil)
+	assert.ErrorContains(t, err, `unsupported disk usage provider "btrfs"`)
+}
@@ -0,0 +1,175 @@
+package journal
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+)
+
+const (
+	// Name of the file used to persist upload entries, stored next to the configuration file.
+	Filename = ".nascliuploads"
+
+	// Verified entries older than this are pruned when the journal is saved.
+	maxVerifiedEntryAge = 90 * 24 * time.Hour
+)
+
+type Status string
+
+const (
+	// Upload was started but never reached the end of the transfer.
+	StatusPending Status = "pending"
+
+	// Transfer completed but remote file has not been verified yet.
+	StatusUploaded Status = "uploaded"
+
+	// Remote file checksum matches the local one.
+	StatusVerified Status = "verified"
+
+	// Remote file checksum does not match the local one and must be sent again from scratch.
+	StatusCorrupted Status = "corrupted"
+)
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// Holds the state of a single file upload.
+type Entry struct {
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	Hash        string    `json:"hash,omitempty"`
+	Status      Status    `json:"status"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Whether entry describes given local file, meaning it has not changed since it was recorded.
+func (e *Entry) Matches(info os.FileInfo) bool {
+	return e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// Persistent record of uploads, safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	entries map[string]*Entry
+	path    string
+}
+
+// Returns the default journal file path.
+func DefaultPath() string {
+	return filepath.Join(config.Dir, Filename)
+}
+
+// Loads the journal stored at given path. A missing file results in an empty journal.
+func Open(path string) (*Journal, error) {
+	j := &Journal{
+		entries: map[string]*Entry{},
+		path:    path,
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return j, nil
+		}
+		return nil, fmt.Errorf("could not read upload journal: %w", err)
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("could not parse upload journal %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		j.entries[key(entry.Source, entry.Destination)] = entry
+	}
+
+	return j, nil
+}
+
+// Returns a copy of the entry recorded for given source and destination, or nil if there is none.
+func (j *Journal) Get(source, destination string) *Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[key(source, destination)]
+	if !ok {
+		return nil
+	}
+	copied := *entry
+	return &copied
+}
+
+// Records given entry and persists the journal to disk.
+func (j *Journal) Put(entry *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	copied := *entry
+	copied.UpdatedAt = time.Now()
+	j.entries[key(entry.Source, entry.Destination)] = &copied
+
+	return j.save()
+}
+
+func (j *Journal) save() error {
+	now := time.Now()
+	entries := slices.Collect(maps.Values(j.entries))
+	entries = slices.DeleteFunc(entries, func(e *Entry) bool {
+		return e.Status == StatusVerified && now.Sub(e.UpdatedAt) > maxVerifiedEntryAge
+	})
+	slices.SortFunc(entries, func(a, b *Entry) int {
+		return cmp.Or(
+			a.UpdatedAt.Compare(b.UpdatedAt),
+			cmp.Compare(a.Destination, b.Destination),
+		)
+	})
+
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode upload journal: %w", err)
+	}
+
+	// Write to a temporary file first so that an interrupted write never corrupts the journal.
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, config.FileMode); err != nil {
+		return fmt.Errorf("could not write upload journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return fmt.Errorf("could not write upload journal: %w", err)
+	}
+
+	return nil
+}
+
+// Computes the hex-encoded SHA-256 checksum of given local file.
+func Hash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func key(source, destination string) string {
+	return source + "\x00" + destination
+}
@@ -0,0 +1,91 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Journal_Persists_Entries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Filename)
+
+	j, err := Open(path)
+	require.NoError(t, err)
+	assert.Nil(t, j.Get("/local/movie.mkv", "/remote/movie.mkv"))
+
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = j.Put(&Entry{
+		Source:      "/local/movie.mkv",
+		Destination: "/remote/movie.mkv",
+		Size:        42,
+		ModTime:     modTime,
+		Hash:        "abc",
+		Status:      StatusVerified,
+	})
+	require.NoError(t, err)
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	entry := reopened.Get("/local/movie.mkv", "/remote/movie.mkv")
+	require.NotNil(t, entry)
+	assert.Equal(t, int64(42), entry.Size)
+	assert.True(t, entry.ModTime.Equal(modTime))
+	assert.Equal(t, "abc", entry.Hash)
+	assert.Equal(t, StatusVerified, entry.Status)
+	assert.Nil(t, reopened.Get("/local/movie.mkv", "/other/movie.mkv"))
+}
+
+func Test_Journal_Prunes_Old_Verified_Entries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Filename)
+
+	j, err := Open(path)
+	require.NoError(t, err)
+
+	j.entries[key("old", "old")] = &Entry{
+		Source:      "old",
+		Destination: "old",
+		Status:      StatusVerified,
+		UpdatedAt:   time.Now().Add(-maxVerifiedEntryAge - time.Hour),
+	}
+	j.entries[key("stale", "stale")] = &Entry{
+		Source:      "stale",
+		Destination: "stale",
+		Status:      StatusPending,
+		UpdatedAt:   time.Now().Add(-maxVerifiedEntryAge - time.Hour),
+	}
+	require.NoError(t, j.Put(&Entry{Source: "new", Destination: "new", Status: StatusVerified}))
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	assert.Nil(t, reopened.Get("old", "old"))
+	assert.NotNil(t, reopened.Get("stale", "stale"))
+	assert.NotNil(t, reopened.Get("new", "new"))
+}
+
+func Test_Entry_Matches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.mkv")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	entry := &Entry{Size: info.Size(), ModTime: info.ModTime()}
+	assert.True(t, entry.Matches(info))
+
+	entry.Size++
+	assert.False(t, entry.Matches(info))
+}
+
+func Test_Hash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.mkv")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	hash, err := Hash(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73", hash)
+}
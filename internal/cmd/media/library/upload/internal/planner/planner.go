@@ -0,0 +1,163 @@
+package planner
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+// Group of files that must be uploaded to the same destination, such as a movie or all episodes of a show.
+type Item struct {
+	// Unique name of the item, e.g. the movie folder or show name.
+	Key string
+
+	// Total size of the item files in bytes.
+	Size int64
+
+	// Destination the item is bound to, e.g. because the show already exists there. Left empty to let the
+	// planner choose.
+	Destination string
+
+	// Destinations the planner must not choose, e.g. because another copy of the item already exists there.
+	Exclude []string
+}
+
+// Remote folder that items can be uploaded to.
+type Destination struct {
+	Path string
+	Free uint64
+
+	// File system holding the destination. Destinations on the same file system share its free space, while an empty
+	// value stands for a file system of its own.
+	Filesystem string
+}
+
+// Distribution of items across destinations.
+type Plan struct {
+	Destinations []*PlannedDestination
+	assignments  map[string]*PlannedDestination
+}
+
+// Destination along with the items planned to be uploaded to it.
+type PlannedDestination struct {
+	Path    string
+	Free    uint64
+	Planned int64
+	Items   []string
+
+	filesystem *filesystem
+}
+
+// Returns the free space left on the destination file system once planned items are uploaded, including the ones
+// planned to other destinations on the same file system.
+func (d *PlannedDestination) Remaining() uint64 {
+	return d.filesystem.free - uint64(d.filesystem.planned)
+}
+
+// Free space shared by destinations on the same file system.
+type filesystem struct {
+	free    uint64
+	planned int64
+}
+
+// Returns the destination path selected for given item key.
+func (p *Plan) Destination(key string) string {
+	if d, ok := p.assignments[key]; ok {
+		return d.Path
+	}
+	return ""
+}
+
+// Distributes items across destinations.
+//
+// Bound items are placed first, then remaining items are placed from the largest to the smallest into the destination
+// with the most free space left, which spreads them evenly. An error is returned if the batch does not fit.
+func New(items []Item, destinations []Destination) (*Plan, error) {
+	plan := &Plan{
+		assignments: make(map[string]*PlannedDestination, len(items)),
+	}
+	byPath := make(map[string]*PlannedDestination, len(destinations))
+	filesystems := map[string]*filesystem{}
+	var totalFree uint64
+	for _, destination := range destinations {
+		key := cmp.Or(destination.Filesystem, "path:"+destination.Path)
+		fs, ok := filesystems[key]
+		if !ok {
+			fs = &filesystem{free: destination.Free}
+			filesystems[key] = fs
+			totalFree += destination.Free
+		}
+		d := &PlannedDestination{Path: destination.Path, Free: destination.Free, filesystem: fs}
+		plan.Destinations = append(plan.Destinations, d)
+		byPath[destination.Path] = d
+	}
+
+	var totalSize int64
+	for _, item := range items {
+		totalSize += item.Size
+	}
+	if uint64(totalSize) > totalFree {
+		return nil, fmt.Errorf(
+			"batch of %s does not fit in the %s available across all destinations",
+			util.FormatBytes(totalSize),
+			util.FormatBytes(int64(totalFree)),
+		)
+	}
+
+	var unbound []Item
+	for _, item := range items {
+		if item.Destination == "" {
+			unbound = append(unbound, item)
+			continue
+		}
+		d, ok := byPath[item.Destination]
+		if !ok {
+			return nil, fmt.Errorf("unknown destination %s for %s", item.Destination, item.Key)
+		}
+		if d.Remaining() < uint64(item.Size) {
+			return nil, fmt.Errorf(
+				"%s (%s) does not fit in %s where it already exists (%s free)",
+				item.Key,
+				util.FormatBytes(item.Size),
+				d.Path,
+				util.FormatBytes(int64(d.Remaining())),
+			)
+		}
+		plan.assign(item, d)
+	}
+
+	slices.SortStableFunc(unbound, func(a, b Item) int {
+		return cmp.Compare(b.Size, a.Size)
+	})
+
+	for _, item := range unbound {
+		var selected *PlannedDestination
+		for _, d := range plan.Destinations {
+			if d.Remaining() < uint64(item.Size) || slices.Contains(item.Exclude, d.Path) {
+				continue
+			}
+			if selected == nil || d.Remaining() > selected.Remaining() {
+				selected = d
+			}
+		}
+		if selected == nil {
+			return nil, fmt.Errorf("%s (%s) does not fit in any destination", item.Key, util.FormatBytes(item.Size))
+		}
+		plan.assign(item, selected)
+	}
+
+	for _, d := range plan.Destinations {
+		slices.Sort(d.Items)
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) assign(item Item, d *PlannedDestination) {
+	d.Planned += item.Size
+	d.filesystem.planned += item.Size
+	d.Items = append(d.Items, item.Key)
+	p.assignments[item.Key] = d
+}
@@ -0,0 +1,120 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New_Spreads_Items(t *testing.T) {
+	plan, err := New(
+		[]Item{
+			{Key: "small", Size: 10},
+			{Key: "large", Size: 60},
+			{Key: "medium", Size: 40},
+		},
+		[]Destination{
+			{Path: "/volume1/movies", Free: 100},
+			{Path: "/volume2/movies", Free: 90},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/volume1/movies", plan.Destination("large"))
+	assert.Equal(t, "/volume2/movies", plan.Destination("medium"))
+	assert.Equal(t, "/volume2/movies", plan.Destination("small"))
+	assert.Equal(t, []string{"large"}, plan.Destinations[0].Items)
+	assert.Equal(t, uint64(40), plan.Destinations[0].Remaining())
+	assert.Equal(t, []string{"medium", "small"}, plan.Destinations[1].Items)
+	assert.Equal(t, uint64(40), plan.Destinations[1].Remaining())
+	assert.Empty(t, plan.Destination("unknown"))
+}
+
+func Test_New_Keeps_Bound_Items(t *testing.T) {
+	plan, err := New(
+		[]Item{
+			{Key: "Existing Show", Size: 50, Destination: "/volume2/tvshows"},
+			{Key: "New Show", Size: 20},
+		},
+		[]Destination{
+			{Path: "/volume1/tvshows", Free: 60},
+			{Path: "/volume2/tvshows", Free: 100},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/volume2/tvshows", plan.Destination("Existing Show"))
+	assert.Equal(t, "/volume1/tvshows", plan.Destination("New Show"))
+}
+
+func Test_New_Skips_Excluded_Destinations(t *testing.T) {
+	plan, err := New(
+		[]Item{{Key: "Movie (2024)", Size: 10, Exclude: []string{"/volume1/movies"}}},
+		[]Destination{
+			{Path: "/volume1/movies", Free: 100},
+			{Path: "/volume2/movies", Free: 50},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/volume2/movies", plan.Destination("Movie (2024)"))
+}
+
+func Test_New_Shares_Free_Space_Of_Same_Filesystem(t *testing.T) {
+	plan, err := New(
+		[]Item{
+			{Key: "large", Size: 60},
+			{Key: "medium", Size: 40},
+			{Key: "small", Size: 30},
+		},
+		[]Destination{
+			{Path: "/tank/movies", Free: 100, Filesystem: "tank"},
+			{Path: "/tank/movies-4k", Free: 100, Filesystem: "tank"},
+			{Path: "/volume1/movies", Free: 50},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/tank/movies", plan.Destination("large"))
+	assert.Equal(t, "/volume1/movies", plan.Destination("medium"))
+	assert.Equal(t, "/tank/movies", plan.Destination("small"))
+	assert.Equal(t, uint64(10), plan.Destinations[0].Remaining())
+	assert.Equal(t, uint64(10), plan.Destinations[1].Remaining())
+	assert.Equal(t, uint64(10), plan.Destinations[2].Remaining())
+}
+
+func Test_New_Refuses_Batch_Too_Large_For_Shared_Filesystem(t *testing.T) {
+	_, err := New(
+		[]Item{{Key: "movie", Size: 60}, {Key: "show", Size: 60}},
+		[]Destination{
+			{Path: "/volume1/movies", Free: 100, Filesystem: "/volume1"},
+			{Path: "/volume1/tvshows", Free: 100, Filesystem: "/volume1"},
+		},
+	)
+	assert.ErrorContains(t, err, "does not fit in the 100 B available across all destinations")
+}
+
+func Test_New_Refuses_Batch_Too_Large(t *testing.T) {
+	_, err := New(
+		[]Item{{Key: "movie", Size: 200}},
+		[]Destination{{Path: "/volume1/movies", Free: 100}, {Path: "/volume2/movies", Free: 50}},
+	)
+	assert.ErrorContains(t, err, "does not fit in the 150 B available across all destinations")
+}
+
+func Test_New_Refuses_Item_Too_Large_For_Any_Destination(t *testing.T) {
+	_, err := New(
+		[]Item{{Key: "movie", Size: 120}},
+		[]Destination{{Path: "/volume1/movies", Free: 100}, {Path: "/volume2/movies", Free: 100}},
+	)
+	assert.ErrorContains(t, err, "movie (120 B) does not fit in any destination")
+}
+
+func Test_New_Refuses_Bound_Item_Too_Large(t *testing.T) {
+	_, err := New(
+		[]Item{{Key: "Existing Show", Size: 80, Destination: "/volume1/tvshows"}},
+		[]Destination{{Path: "/volume1/tvshows", Free: 50}, {Path: "/volume2/tvshows", Free: 100}},
+	)
+	assert.ErrorContains(t, err, "does not fit in /volume1/tvshows where it already exists")
+}
@@ -0,0 +1,119 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+// Options passed to ffprobe so that it only describes the first video stream of a file.
+var options = []string{
+	"-v", "error",
+	"-select_streams", "v:0",
+	"-show_entries", "stream=codec_name,width,height,bit_rate:format=bit_rate",
+	"-of", "json",
+}
+
+// Properties of the main video stream of a file, used to compare two releases of the same media.
+type Details struct {
+	Width   int
+	Height  int
+	Codec   string
+	BitRate int64
+}
+
+// Returns the resolution of the video, e.g. 1920x1080.
+func (d *Details) Resolution() string {
+	if d.Width == 0 || d.Height == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%dx%d", d.Width, d.Height)
+}
+
+// Returns the bit rate of the video in a human-readable format, e.g. 4.2 Mb/s.
+func (d *Details) FormatBitRate() string {
+	switch {
+	case d.BitRate <= 0:
+		return "unknown"
+	case d.BitRate >= 1_000_000:
+		return fmt.Sprintf("%.1f Mb/s", float64(d.BitRate)/1_000_000)
+	default:
+		return fmt.Sprintf("%d kb/s", d.BitRate/1_000)
+	}
+}
+
+// Whether the video has more pixels than given one, or as many pixels at a higher bit rate.
+func (d *Details) IsBetterThan(other *Details) bool {
+	pixels, otherPixels := d.Width*d.Height, other.Width*other.Height
+	if pixels != otherPixels {
+		return pixels > otherPixels
+	}
+	return d.BitRate > other.BitRate
+}
+
+// Probes given local file with ffprobe.
+func Local(ctx context.Context, path string) (*Details, error) {
+	ffprobe := exec.CommandContext(ctx, cmdutil.CommandFFprobe, append(options, path)...)
+	output, err := ffprobe.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not probe %s: %w", path, err)
+	}
+	return Parse(output)
+}
+
+// Probes given remote file with the ffprobe command available on the server.
+func Remote(path string) (*Details, error) {
+	output, err := svc.SSH.SendCommands(fmt.Sprintf("%s %s %s",
+		cmdutil.CommandFFprobe,
+		strings.Join(options, " "),
+		util.ShellQuote(path),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("could not probe %s: %w", path, err)
+	}
+	return Parse(output)
+}
+
+// Parses the JSON output of ffprobe. The container bit rate is used when the video stream does not declare its own,
+// which is common with Matroska files.
+func Parse(output []byte) (*Details, error) {
+	var result struct {
+		Streams []struct {
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+			BitRate   string `json:"bit_rate"`
+		} `json:"streams"`
+		Format struct {
+			BitRate string `json:"bit_rate"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("could not parse ffprobe output: %w", err)
+	}
+	if len(result.Streams) == 0 {
+		return nil, fmt.Errorf("no video stream found")
+	}
+
+	stream := result.Streams[0]
+	details := &Details{
+		Width:  stream.Width,
+		Height: stream.Height,
+		Codec:  stream.CodecName,
+	}
+	for _, bitRate := range []string{stream.BitRate, result.Format.BitRate} {
+		if value, err := strconv.ParseInt(bitRate, 10, 64); err == nil && value > 0 {
+			details.BitRate = value
+			break
+		}
+	}
+
+	return details, nil
+}
@@ -0,0 +1,69 @@
+package probe
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+func Test_Parse_Uses_Stream_Bit_Rate(t *testing.T) {
+	details, err := Parse([]byte(`{
+		"streams": [{"codec_name": "h264", "width": 1280, "height": 720, "bit_rate": "2500000"}],
+		"format": {"bit_rate": "3000000"}
+	}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, &Details{Width: 1280, Height: 720, Codec: "h264", BitRate: 2_500_000}, details)
+	assert.Equal(t, "1280x720", details.Resolution())
+	assert.Equal(t, "2.5 Mb/s", details.FormatBitRate())
+}
+
+func Test_Parse_Falls_Back_To_Format_Bit_Rate(t *testing.T) {
+	details, err := Parse([]byte(`{
+		"streams": [{"codec_name": "hevc", "width": 1920, "height": 1080}],
+		"format": {"bit_rate": "850000"}
+	}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, &Details{Width: 1920, Height: 1080, Codec: "hevc", BitRate: 850_000}, details)
+	assert.Equal(t, "850 kb/s", details.FormatBitRate())
+}
+
+func Test_Parse_Fails_Without_Video_Stream(t *testing.T) {
+	_, err := Parse([]byte(`{"streams": [], "format": {}}`))
+
+	assert.ErrorContains(t, err, "no video stream found")
+}
+
+func Test_Is_Better_Than_Compares_Resolution_Then_Bit_Rate(t *testing.T) {
+	hd := &Details{Width: 1280, Height: 720, BitRate: 4_000_000}
+	fullHD := &Details{Width: 1920, Height: 1080, BitRate: 2_000_000}
+	fullHDHigherBitRate := &Details{Width: 1920, Height: 1080, BitRate: 3_000_000}
+
+	assert.True(t, fullHD.IsBetterThan(hd))
+	assert.False(t, hd.IsBetterThan(fullHD))
+	assert.True(t, fullHDHigherBitRate.IsBetterThan(fullHD))
+	assert.False(t, fullHD.IsBetterThan(fullHD))
+}
+
+func Test_Remote_Runs_FFprobe_On_Server(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.HandleCommand("ffprobe ", func(cmd string, stdout, stderr io.Writer) int {
+		fmt.Fprint(stdout, `{"streams": [{"codec_name": "h264", "width": 1280, "height": 720}], "format": {"bit_rate": "2000000"}}`)
+		return 0
+	})
+
+	details, err := Remote("/volume1/tvshows/Show/Season 1/Show - 1x01.mkv")
+
+	require.NoError(t, err)
+	assert.Equal(t, &Details{Width: 1280, Height: 720, Codec: "h264", BitRate: 2_000_000}, details)
+	commands := server.Commands()
+	require.Len(t, commands, 1)
+	assert.True(t, strings.HasSuffix(commands[0], ` -of json '/volume1/tvshows/Show/Season 1/Show - 1x01.mkv'`))
+}
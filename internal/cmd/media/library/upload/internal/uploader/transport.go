@@ -0,0 +1,154 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/thediveo/enumflag/v2"
+	"golang.org/x/sync/errgroup"
+
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+)
+
+// Defines the transport enumeration type.
+type Transport enumflag.Flag
+
+// Enumeration values for the Transport type.
+const (
+	TransportRsync Transport = iota
+	TransportSFTP
+)
+
+// Maps enumeration values to their textual representations.
+var TransportIDs = map[Transport][]string{
+	TransportRsync: {"rsync"},
+	TransportSFTP:  {"sftp"},
+}
+
+func (t Transport) String() string {
+	return TransportIDs[t][0]
+}
+
+// Returns the transport matching given textual representation.
+func ParseTransport(s string) (Transport, error) {
+	for transport, ids := range TransportIDs {
+		for _, id := range ids {
+			if id == s {
+				return transport, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unsupported transport %q", s)
+}
+
+const (
+	defaultSFTPChunkSize   = 4 * 1024 * 1024
+	defaultSFTPParallelism = 4
+)
+
+// Streams the video file over the SFTP connection, resuming from the size of the remote file if it already exists.
+// Progress is reported in bytes, the tracker total being expected to be the file size.
+//
+// Chunks are written concurrently but the remote file is truncated to the last contiguous chunk on failure, so
+// that resuming from its size never leaves holes.
+func (p *process) transferWithSFTP(ctx context.Context) error {
+	src, err := os.Open(p.file.FilePath())
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read file information: %w", err)
+	}
+	size := srcInfo.Size()
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+	dst, err := client.OpenFile(p.destination, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer dst.Close()
+
+	dstInfo, err := dst.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read remote file information: %w", err)
+	}
+	offset := dstInfo.Size()
+	if offset > size {
+		if err := dst.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate remote file: %w", err)
+		}
+		offset = 0
+	}
+
+	chunkSize := int64(p.sftpChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = defaultSFTPChunkSize
+	}
+	parallelism := p.sftpParallelism
+	if parallelism <= 0 {
+		parallelism = defaultSFTPParallelism
+	}
+
+	chunksCount := int((size - offset + chunkSize - 1) / chunkSize)
+	done := make([]bool, chunksCount)
+	contiguous := 0
+	transferred := offset
+	mu := sync.Mutex{}
+
+	p.tracker.SetValue(transferred)
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(parallelism)
+
+	for index := range chunksCount {
+		if egCtx.Err() != nil {
+			break
+		}
+		eg.Go(func() error {
+			chunkOffset := offset + int64(index)*chunkSize
+			buf := make([]byte, min(chunkSize, size-chunkOffset))
+
+			if _, err := src.ReadAt(buf, chunkOffset); err != nil {
+				return fmt.Errorf("failed to read file at offset %d: %w", chunkOffset, err)
+			}
+			if err := egCtx.Err(); err != nil {
+				return err
+			}
+			if _, err := dst.WriteAt(buf, chunkOffset); err != nil {
+				return fmt.Errorf("failed to write remote file at offset %d: %w", chunkOffset, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			done[index] = true
+			for contiguous < chunksCount && done[contiguous] {
+				contiguous++
+			}
+			transferred += int64(len(buf))
+			p.tracker.SetValue(transferred)
+
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		watermark := min(offset+int64(contiguous)*chunkSize, size)
+		if truncateErr := dst.Truncate(watermark); truncateErr != nil {
+			return fmt.Errorf("%w (could not truncate remote file: %v)", err, truncateErr)
+		}
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -16,9 +16,12 @@ import (
 	"time"
 
 	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/journal"
 	"github.com/jeremiergz/nas-cli/internal/config"
 	"github.com/jeremiergz/nas-cli/internal/image"
 	"github.com/jeremiergz/nas-cli/internal/media"
@@ -35,13 +38,18 @@ type process struct {
 	destination      string
 	file             media.MediaFile
 	imageFiles       []*image.Image
+	journal          *journal.Journal
 	keepOriginal     bool
 	kind             media.Kind
 	ownerUID         int
 	ownerGID         int
 	permissionsDepth uint
 	remoteHost       string
+	sftpChunkSize    int
+	sftpParallelism  int
 	tracker          *progress.Tracker
+	transport        Transport
+	verify           bool
 	w                io.Writer
 }
 
@@ -52,17 +60,25 @@ func New(
 	destDir string,
 	keepOriginal bool,
 	permissionsDepth uint,
+	uploadJournal *journal.Journal,
+	verify bool,
+	transport Transport,
 ) svc.Runnable {
 	return &process{
 		destination:      destDir,
 		file:             file,
 		imageFiles:       imageFiles,
+		journal:          uploadJournal,
 		keepOriginal:     keepOriginal,
 		kind:             kind,
 		ownerUID:         viper.GetInt(config.KeySCPChownUID),
 		ownerGID:         viper.GetInt(config.KeySCPChownGID),
 		permissionsDepth: permissionsDepth,
 		remoteHost:       viper.GetString(config.KeySSHHost),
+		sftpChunkSize:    viper.GetInt(config.KeySCPSFTPChunkSize),
+		sftpParallelism:  viper.GetInt(config.KeySCPSFTPParallelism),
+		transport:        transport,
+		verify:           verify,
 		w:                os.Stdout,
 	}
 }
@@ -99,57 +115,56 @@ func (p *process) Run(ctx context.Context) error {
 
 	p.tracker.Start()
 
-	remoteParentDir := filepath.Dir(p.destination)
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		p.tracker.MarkAsErrored()
+		return err
+	}
 
-	err := svc.SFTP.Client.MkdirAll(remoteParentDir)
+	entry, err := p.journalEntry()
 	if err != nil {
 		p.tracker.MarkAsErrored()
-		return fmt.Errorf("failed to create remote directory: %w", err)
+		return err
 	}
-	p.tracker.SetValue(1)
 
-	options := []string{
-		"--append",
-		"--inplace",
-		"--no-perms",
-		"--no-times",
-		"--progress",
-		p.file.FilePath(),
-		fmt.Sprintf("%s:%q", p.remoteHost, remoteParentDir),
+	// A corrupted remote file cannot be resumed, it must be sent again from scratch.
+	if entry.Status == journal.StatusCorrupted {
+		err := client.Remove(p.destination)
+		if err != nil && !os.IsNotExist(err) {
+			p.tracker.MarkAsErrored()
+			return fmt.Errorf("failed to remove corrupted remote file: %w", err)
+		}
 	}
 
-	rsync := exec.CommandContext(ctx, cmdutil.CommandRsync, options...)
+	if err := p.record(entry, journal.StatusPending); err != nil {
+		p.tracker.MarkAsErrored()
+		return err
+	}
 
-	bufOut := &syncBuffer{}
-	bufErr := new(bytes.Buffer)
-	rsync.Stdout = bufOut
-	rsync.Stderr = bufErr
+	remoteParentDir := filepath.Dir(p.destination)
 
-	if err := rsync.Start(); err != nil {
+	err = client.MkdirAll(remoteParentDir)
+	if err != nil {
+		p.tracker.MarkAsErrored()
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+
+	switch p.transport {
+	case TransportSFTP:
+		err = p.transferWithSFTP(ctx)
+	default:
+		err = p.transferWithRsync(ctx, remoteParentDir)
+	}
+	if err != nil {
 		p.tracker.MarkAsErrored()
 		return err
 	}
 
-	go func() {
-		for !p.tracker.IsDone() {
-			progress, err := getRsyncProgress(bufOut.StringAndReset())
-			if err == nil {
-				// Keep the progress under 99 because the last 1% is for changing permissions.
-				if progress > 1 && progress <= 99 {
-					p.tracker.SetValue(int64(progress))
-				}
-			}
-			time.Sleep(100 * time.Millisecond)
+	if p.verify {
+		if err := p.verifyChecksum(ctx, entry); err != nil {
+			p.tracker.MarkAsErrored()
+			return err
 		}
-	}()
-
-	if err := rsync.Wait(); err != nil {
-		p.tracker.MarkAsErrored()
-		return util.ErrorFromStrings(
-			fmt.Errorf("failed to run Rsync: %w", err),
-			bufOut.String(),
-			bufErr.String(),
-		)
 	}
 
 	entriesToChangePermsFor := map[string]fs.FileMode{}
@@ -193,26 +208,12 @@ func (p *process) Run(ctx context.Context) error {
 		entriesToChangePermsFor[imageDestFilePath] = config.FileMode
 	}
 
-	eg, _ := errgroup.WithContext(ctx)
-	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
-
-	for entry, chmod := range entriesToChangePermsFor {
-		eg.Go(func() error {
-			err := svc.SFTP.Client.Chmod(entry, chmod)
-			if err != nil {
-				return err
-			}
-			return nil
-		})
-		eg.Go(func() error {
-			err := svc.SFTP.Client.Chown(entry, p.ownerUID, p.ownerGID)
-			if err != nil {
-				return err
-			}
-			return nil
-		})
+	if err := remote.SetPermissions(ctx, entriesToChangePermsFor, p.ownerUID, p.ownerGID); err != nil {
+		p.tracker.MarkAsErrored()
+		return err
 	}
-	if err := eg.Wait(); err != nil {
+
+	if err := p.record(entry, lo.Ternary(p.verify, journal.StatusVerified, journal.StatusUploaded)); err != nil {
 		p.tracker.MarkAsErrored()
 		return err
 	}
@@ -228,10 +229,139 @@ func (p *process) Run(ctx context.Context) error {
 	return nil
 }
 
+// Sends the video file using Rsync, parsing its output to track progress as a percentage, the tracker total being
+// expected to be 100.
+func (p *process) transferWithRsync(ctx context.Context, remoteParentDir string) error {
+	options := []string{
+		"--append",
+		"--inplace",
+		"--no-perms",
+		"--no-times",
+		"--progress",
+		p.file.FilePath(),
+		fmt.Sprintf("%s:%q", p.remoteHost, remoteParentDir),
+	}
+
+	rsync := exec.CommandContext(ctx, cmdutil.CommandRsync, options...)
+
+	bufOut := &syncBuffer{}
+	bufErr := new(bytes.Buffer)
+	rsync.Stdout = bufOut
+	rsync.Stderr = bufErr
+
+	if err := rsync.Start(); err != nil {
+		return err
+	}
+	p.tracker.SetValue(1)
+
+	go func() {
+		for !p.tracker.IsDone() {
+			progress, err := getRsyncProgress(bufOut.StringAndReset())
+			if err == nil {
+				// Keep the progress under 99 because the last 1% is for changing permissions.
+				if progress > 1 && progress <= 99 {
+					p.tracker.SetValue(int64(progress))
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	if err := rsync.Wait(); err != nil {
+		return util.ErrorFromStrings(
+			fmt.Errorf("failed to run Rsync: %w", err),
+			bufOut.String(),
+			bufErr.String(),
+		)
+	}
+
+	return nil
+}
+
+// Returns the journal entry matching this upload, or a new one if the local file changed since it was recorded.
+func (p *process) journalEntry() (*journal.Entry, error) {
+	info, err := os.Stat(p.file.FilePath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file information: %w", err)
+	}
+
+	if p.journal != nil {
+		entry := p.journal.Get(p.file.FilePath(), p.destination)
+		if entry != nil && entry.Matches(info) {
+			return entry, nil
+		}
+	}
+
+	return &journal.Entry{
+		Source:      p.file.FilePath(),
+		Destination: p.destination,
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// Sets given status on the entry and persists it if a journal is used.
+func (p *process) record(entry *journal.Entry, status journal.Status) error {
+	entry.Status = status
+	if p.journal == nil {
+		return nil
+	}
+	if err := p.journal.Put(entry); err != nil {
+		return fmt.Errorf("failed to update upload journal: %w", err)
+	}
+	return nil
+}
+
+// Compares local and remote SHA-256 checksums of the uploaded file.
+func (p *process) verifyChecksum(_ context.Context, entry *journal.Entry) error {
+	var localHash, remoteHash string
+
+	eg := errgroup.Group{}
+	eg.Go(func() error {
+		if entry.Hash != "" {
+			localHash = entry.Hash
+			return nil
+		}
+		hash, err := journal.Hash(p.file.FilePath())
+		if err != nil {
+			return fmt.Errorf("failed to compute local checksum: %w", err)
+		}
+		localHash = hash
+		return nil
+	})
+	eg.Go(func() error {
+		hash, err := remote.Checksum(p.destination)
+		if err != nil {
+			return fmt.Errorf("failed to compute remote checksum: %w", err)
+		}
+		remoteHash = hash
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	entry.Hash = localHash
+	if !strings.EqualFold(localHash, remoteHash) {
+		if err := p.record(entry, journal.StatusCorrupted); err != nil {
+			return err
+		}
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", p.destination, localHash, remoteHash)
+	}
+
+	return nil
+}
+
 func (p *process) uploadImageFile(_ context.Context, imageFilePath, targetFilePath string) error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		p.tracker.MarkAsErrored()
+		return err
+	}
+
 	remoteDir := filepath.Dir(targetFilePath)
 	if remoteDir != "." {
-		err := svc.SFTP.Client.MkdirAll(remoteDir)
+		err := client.MkdirAll(remoteDir)
 		if err != nil {
 			p.tracker.MarkAsErrored()
 			return fmt.Errorf("failed to create remote directory: %w", err)
@@ -244,7 +374,7 @@ func (p *process) uploadImageFile(_ context.Context, imageFilePath, targetFilePa
 	}
 	defer src.Close()
 
-	dst, err := svc.SFTP.Client.OpenFile(targetFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	dst, err := client.OpenFile(targetFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
 	if err != nil {
 		return fmt.Errorf("failed to open remote file: %w", err)
 	}
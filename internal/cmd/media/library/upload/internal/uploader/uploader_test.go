@@ -0,0 +1,109 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/journal"
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+const (
+	testContent     = "0123456789abcdefghijklmnopqrstuvwxyz"
+	testDestination = "/volume1/movies/Movie (2024)/Movie (2024).mkv"
+)
+
+func setupUpload(t *testing.T) (*servicetest.Server, media.MediaFile, *journal.Journal) {
+	t.Helper()
+
+	server := servicetest.NewServer(t)
+	configtest.Set(t, map[string]any{
+		config.KeySCPSFTPChunkSize:   4,
+		config.KeySCPSFTPParallelism: 3,
+	})
+
+	wd := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "Movie (2024).mkv"), []byte(testContent), 0o644))
+	movies, err := media.ListMovies(wd, []string{util.ExtensionMKV}, false)
+	require.NoError(t, err)
+	require.Len(t, movies, 1)
+
+	uploadJournal, err := journal.Open(filepath.Join(t.TempDir(), "journal.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SFTP.Connect())
+
+	return server, movies[0], uploadJournal
+}
+
+func run(file media.MediaFile, uploadJournal *journal.Journal) error {
+	return runWithTracker(file, uploadJournal, newTracker())
+}
+
+func runWithTracker(file media.MediaFile, uploadJournal *journal.Journal, tracker *progress.Tracker) error {
+	return New(media.KindMovie, file, nil, testDestination, true, 1, uploadJournal, true, TransportSFTP).
+		SetOutput(io.Discard).
+		SetTracker(tracker).
+		Run(context.Background())
+}
+
+func newTracker() *progress.Tracker {
+	return &progress.Tracker{Total: int64(len(testContent)), AutoStopDisabled: true}
+}
+
+func Test_Run_Resumes_And_Verifies_SFTP_Upload(t *testing.T) {
+	server, file, uploadJournal := setupUpload(t)
+	server.WriteFile(t, testDestination, testContent[:10])
+	tracker := newTracker()
+
+	require.NoError(t, runWithTracker(file, uploadJournal, tracker))
+
+	assert.Equal(t, testContent, server.ReadFile(t, testDestination))
+	info, err := os.Stat(server.LocalPath(testDestination))
+	require.NoError(t, err)
+	assert.Equal(t, config.FileMode, info.Mode().Perm())
+	assert.Contains(t, server.Commands(), "sha256sum "+util.ShellQuote(testDestination))
+	assert.Equal(t, journal.StatusVerified, uploadJournal.Get(file.FilePath(), testDestination).Status)
+	assert.Equal(t, int64(len(testContent)), tracker.Value())
+	assert.Equal(t, int64(len(testContent)), tracker.Total)
+}
+
+func Test_Run_Marks_Corrupted_Upload(t *testing.T) {
+	server, file, uploadJournal := setupUpload(t)
+	server.HandleCommand("sha256sum ", func(cmd string, stdout, stderr io.Writer) int {
+		fmt.Fprintf(stdout, "%s  %s\n", strings.Repeat("0", 64), testDestination)
+		return 0
+	})
+
+	err := run(file, uploadJournal)
+
+	assert.ErrorContains(t, err, "checksum mismatch for "+testDestination)
+	assert.Equal(t, journal.StatusCorrupted, uploadJournal.Get(file.FilePath(), testDestination).Status)
+}
+
+func Test_Run_Reports_Remote_Checksum_Failure(t *testing.T) {
+	server, file, uploadJournal := setupUpload(t)
+	server.HandleCommand("sha256sum ", func(cmd string, stdout, stderr io.Writer) int {
+		fmt.Fprintln(stderr, "sha256sum: permission denied")
+		return 1
+	})
+
+	err := run(file, uploadJournal)
+
+	assert.ErrorContains(t, err, "failed to compute remote checksum")
+	assert.ErrorContains(t, err, "sha256sum: permission denied")
+}
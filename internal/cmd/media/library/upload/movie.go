@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pterm/pterm"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/thediveo/enumflag/v2"
 
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/planner"
 	"github.com/jeremiergz/nas-cli/internal/config"
 	"github.com/jeremiergz/nas-cli/internal/media"
 	"github.com/jeremiergz/nas-cli/internal/util"
@@ -92,20 +95,82 @@ func processMovies(ctx context.Context, out io.Writer) error {
 		media.SortMoviesByName(movies)
 	}
 
-	uploads := make([]*upload, len(movies))
-	for i, movie := range movies {
-		movieDirname := strings.TrimSuffix(movie.FullName(), fmt.Sprintf(".%s", movie.Extension()))
+	existingMovies, err := findExistingMovies(movies)
+	if err != nil {
+		return err
+	}
+
+	p := newPrompter()
+	var selected []*media.Movie
+	var items []planner.Item
+	replaced := map[string]*existingMovie{}
+	for _, movie := range movies {
+		size, err := localFilesSize([]media.MediaFile{movie})
+		if err != nil {
+			return err
+		}
+		item := planner.Item{
+			Key:  movieDirname(movie),
+			Size: size,
+		}
+
+		if existing := existingMovies[movieDirname(movie)]; len(existing) > 0 {
+			toReplace, skip, err := resolveExistingMovie(ctx, out, p, movie, existing)
+			if err != nil {
+				return err
+			}
+			if skip {
+				continue
+			}
+			if toReplace != nil {
+				item.Destination = toReplace.item.Root
+				replaced[item.Key] = toReplace
+			} else {
+				// Keeping both copies must not overwrite the existing one.
+				for _, e := range existing {
+					if e.Name == item.Key {
+						item.Exclude = append(item.Exclude, filepath.Clean(e.Root))
+					}
+				}
+				if len(item.Exclude) > 0 && lo.EveryBy(remoteFolders, func(folder string) bool {
+					return slices.Contains(item.Exclude, filepath.Clean(folder))
+				}) {
+					return fmt.Errorf("cannot keep both copies of %s: every movies destination already holds it", item.Key)
+				}
+			}
+		}
+
+		selected = append(selected, movie)
+		items = append(items, item)
+	}
+
+	if len(selected) == 0 {
+		pterm.Success.Println("Nothing to upload")
+		return nil
+	}
+
+	plan, err := planDestinations(items)
+	if err != nil {
+		return err
+	}
+
+	uploads := make([]*upload, len(selected))
+	for i, movie := range selected {
 		uploads[i] = &upload{
 			File:        movie,
-			Destination: filepath.Join(remoteDirWithLowestUsage, movieDirname, movie.Basename()),
+			Destination: filepath.Join(plan.Destination(movieDirname(movie)), movieDirname(movie), movie.Basename()),
 			DisplayName: movie.FullName(),
 		}
+		if toReplace, ok := replaced[movieDirname(movie)]; ok {
+			uploads[i].Destination = toReplace.destination(movie)
+			uploads[i].Replaces = toReplace.replacedFiles()
+		}
 		if len(movie.Images()) > 0 {
 			uploads[i].ImageFiles = movie.Images()
 		}
 	}
 
-	err = process(ctx, out, uploads, media.KindMovie)
+	err = process(ctx, out, uploads, media.KindMovie, plan)
 	if err != nil {
 		return err
 	}
@@ -113,6 +178,11 @@ func processMovies(ctx context.Context, out io.Writer) error {
 	return nil
 }
 
+// Returns the name of the folder holding given movie, e.g. "Title (Year)".
+func movieDirname(movie *media.Movie) string {
+	return strings.TrimSuffix(movie.FullName(), fmt.Sprintf(".%s", movie.Extension()))
+}
+
 func sortByCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return []string{
 		"year\tsort by year",
@@ -7,9 +7,9 @@ import (
 	"path/filepath"
 
 	"github.com/pterm/pterm"
-	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/planner"
 	"github.com/jeremiergz/nas-cli/internal/config"
 	"github.com/jeremiergz/nas-cli/internal/image"
 	"github.com/jeremiergz/nas-cli/internal/media"
@@ -99,17 +99,39 @@ func processShows(ctx context.Context, out io.Writer, kind media.Kind) error {
 		return err
 	}
 
+	// All episodes of a show are kept together, existing shows being completed in place.
+	items := make([]planner.Item, len(shows))
+	for i, show := range shows {
+		var episodes []media.MediaFile
+		for _, season := range show.Seasons() {
+			for _, episode := range season.Episodes() {
+				episodes = append(episodes, episode)
+			}
+		}
+		size, err := localFilesSize(episodes)
+		if err != nil {
+			return err
+		}
+		items[i] = planner.Item{
+			Key:  show.Name(),
+			Size: size,
+		}
+		if remoteShowPath, exists := remoteShows[show.Name()]; exists {
+			items[i].Destination = filepath.Dir(remoteShowPath)
+		}
+	}
+	plan, err := planDestinations(items)
+	if err != nil {
+		return err
+	}
+
 	uploads := []*upload{}
 	for _, show := range shows {
 		var imagesToUpload []*image.Image
 		if len(show.Images()) > 0 {
 			imagesToUpload = show.Images()
 		}
-		remoteShowPath, exists := remoteShows[show.Name()]
-		showDir := lo.Ternary(exists,
-			remoteShowPath,
-			filepath.Join(remoteDirWithLowestUsage, show.Name()),
-		)
+		showDir := filepath.Join(plan.Destination(show.Name()), show.Name())
 
 		hasAddedImagesToUpload := false
 		for _, season := range show.Seasons() {
@@ -138,7 +160,13 @@ func processShows(ctx context.Context, out io.Writer, kind media.Kind) error {
 		}
 	}
 
-	err = process(ctx, out, uploads, kind)
+	if upgrade {
+		if err := findReplacedEpisodes(uploads); err != nil {
+			return err
+		}
+	}
+
+	err = process(ctx, out, uploads, kind, plan)
 	if err != nil {
 		return err
 	}
@@ -147,9 +175,14 @@ func processShows(ctx context.Context, out io.Writer, kind media.Kind) error {
 }
 
 func listRemoteShows(paths []string) (map[string]string, error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
 	shows := map[string]string{}
 	for _, path := range paths {
-		paths, err := svc.SFTP.Client.ReadDir(path)
+		paths, err := client.ReadDir(path)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,190 @@
+package upload
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/probe"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+var (
+	// Probes are variables so that tests do not depend on ffprobe being installed.
+	probeLocal  = probe.Local
+	probeRemote = probe.Remote
+)
+
+// Remote file about to be replaced by an upload, described along with the new release to compare them.
+type upgradeCandidate struct {
+	upload     *upload
+	path       string
+	size       int64
+	newSize    int64
+	details    *probe.Details
+	newDetails *probe.Details
+}
+
+// Whether the new release has a higher resolution or bit rate.
+func (c *upgradeCandidate) isUpgrade() bool {
+	return isBetterRelease(c.newDetails, c.details)
+}
+
+// Whether a release with given details has a higher resolution or bit rate than one with the other details. Releases
+// that could not be probed never compare as better, so that nothing gets replaced on a guess.
+func isBetterRelease(details, other *probe.Details) bool {
+	return details != nil && other != nil && details.IsBetterThan(other)
+}
+
+// Sets remote files holding the same episodes as given uploads as replaced by them, whatever their release name.
+func findReplacedEpisodes(uploads []*upload) error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	entriesByDir := map[string][]os.FileInfo{}
+	for _, u := range uploads {
+		episode, ok := u.File.(*media.Episode)
+		if !ok {
+			continue
+		}
+
+		dir := filepath.Dir(u.Destination)
+		entries, ok := entriesByDir[dir]
+		if !ok {
+			var err error
+			entries, err = client.ReadDir(dir)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read %s: %w", dir, err)
+			}
+			entriesByDir[dir] = entries
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !util.IsVideo(entry.Name()) {
+				continue
+			}
+			seasonNumber, episodeNumber, ok := episodeNumbers(entry.Name())
+			if ok && seasonNumber == episode.Season().Index() && episodeNumber == episode.Index() {
+				u.Replaces = append(u.Replaces, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns season and episode numbers of given episode filename, guessing them from its release name if it does not
+// follow naming conventions.
+func episodeNumbers(basename string) (seasonNumber, episodeNumber int, ok bool) {
+	_, seasonNumber, episodeNumber, _, err := media.ParseEpisodeFilename(basename)
+	if err != nil {
+		_, seasonNumber, episodeNumber, err = media.GuessEpisode(basename)
+	}
+	return seasonNumber, episodeNumber, err == nil
+}
+
+// Probes files replaced by given uploads and their new releases. Files that cannot be probed are reported as unknown
+// rather than failing the upload.
+func listUpgradeCandidates(ctx context.Context, uploads []*upload) ([]*upgradeCandidate, error) {
+	var candidates []*upgradeCandidate
+	for _, u := range uploads {
+		for _, path := range u.Replaces {
+			candidates = append(candidates, &upgradeCandidate{upload: u, path: path})
+		}
+	}
+
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(cmdutil.MaxConcurrentGoroutines)
+	for _, c := range candidates {
+		eg.Go(func() error {
+			info, err := client.Stat(c.path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", c.path, err)
+			}
+			c.size = info.Size()
+			c.details, _ = probeRemote(c.path)
+
+			localInfo, err := os.Stat(c.upload.File.FilePath())
+			if err != nil {
+				return fmt.Errorf("could not read %s information: %w", c.upload.File.Basename(), err)
+			}
+			c.newSize = localInfo.Size()
+			c.newDetails, _ = probeLocal(ctx, c.upload.File.FilePath())
+
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// Prints a table comparing every replaced file with its new release, and warns about the ones not being upgrades.
+func printUpgrades(out io.Writer, candidates []*upgradeCandidate) error {
+	var rows [][]string
+	var notUpgrades int
+	for _, c := range candidates {
+		rows = append(rows,
+			upgradeRow(pterm.Red("old"), c.path, c.details, c.size),
+			upgradeRow(pterm.Green("new"), c.upload.File.Basename(), c.newDetails, c.newSize),
+		)
+		if !c.isUpgrade() {
+			notUpgrades++
+		}
+	}
+
+	table, err := renderComparison(rows)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, table)
+
+	if notUpgrades > 0 {
+		pterm.Warning.Printfln("%d new release(s) are not better than the file they replace", notUpgrades)
+	}
+
+	return nil
+}
+
+// Renders a table comparing releases, made of rows returned by upgradeRow.
+func renderComparison(rows [][]string) (string, error) {
+	data := append(pterm.TableData{{"", "File", "Resolution", "Codec", "Bit rate", "Size"}}, rows...)
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return "", fmt.Errorf("could not render comparison table: %w", err)
+	}
+	return table, nil
+}
+
+// Describes a release of given path, labelled as the old or new one, as a comparison table row.
+func upgradeRow(label, path string, details *probe.Details, size int64) []string {
+	if details == nil {
+		details = &probe.Details{}
+	}
+	return []string{
+		label,
+		path,
+		details.Resolution(),
+		cmp.Or(details.Codec, "unknown"),
+		details.FormatBitRate(),
+		util.FormatBytes(size),
+	}
+}
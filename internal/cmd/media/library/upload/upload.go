@@ -2,15 +2,14 @@ package upload
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"slices"
-	"strconv"
 	"strings"
 	"time"
 
@@ -19,15 +18,22 @@ import (
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/diskusage"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/journal"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/planner"
 	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/uploader"
 	"github.com/jeremiergz/nas-cli/internal/config"
 	"github.com/jeremiergz/nas-cli/internal/image"
 	"github.com/jeremiergz/nas-cli/internal/media"
 	"github.com/jeremiergz/nas-cli/internal/prompt"
 	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/plex"
 	"github.com/jeremiergz/nas-cli/internal/service/str"
+	"github.com/jeremiergz/nas-cli/internal/util"
 	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
 	"github.com/jeremiergz/nas-cli/internal/util/fsutil"
 )
@@ -37,11 +43,15 @@ var (
 	delete      bool
 	maxParallel int
 	recursive   bool
+	refresh     bool
+	resume      bool
+	transport   uploader.Transport
+	upgrade     bool
+	verify      bool
 	yes         bool
 
-	remoteDirWithLowestUsage string
-	remoteDiskUsageStats     map[string]int
-	remoteFolders            []string
+	remoteDiskUsages map[string]*diskusage.Usage
+	remoteFolders    []string
 
 	selectedCommand string
 )
@@ -63,9 +73,21 @@ func New() *cobra.Command {
 				return err
 			}
 
+			if !cmd.Flags().Changed("transport") {
+				transport, err = uploader.ParseTransport(viper.GetString(config.KeySCPTransport))
+				if err != nil {
+					return fmt.Errorf("invalid %s configuration entry: %w", config.KeySCPTransport, err)
+				}
+			}
+
 			requiredCommands := []string{
 				cmdutil.CommandExifTool,
-				cmdutil.CommandRsync,
+			}
+			if transport == uploader.TransportRsync {
+				requiredCommands = append(requiredCommands, cmdutil.CommandRsync)
+			}
+			if upgrade {
+				requiredCommands = append(requiredCommands, cmdutil.CommandFFprobe)
 			}
 			for _, command := range requiredCommands {
 				_, err = exec.LookPath(command)
@@ -102,6 +124,9 @@ func New() *cobra.Command {
 			if viper.GetString(config.KeyNASFQDN) == "" {
 				return fmt.Errorf("%s configuration entry is missing", config.KeyNASFQDN)
 			}
+			if upgrade && viper.GetString(config.KeySCPTrashPath) == "" {
+				return fmt.Errorf("%s configuration entry is missing", config.KeySCPTrashPath)
+			}
 
 			// Exit if files/folders retrieved from assets do not exist.
 			for _, asset := range args {
@@ -112,6 +137,7 @@ func New() *cobra.Command {
 				}
 			}
 
+			svc.SSH.SetPrompter(newPrompter())
 			err = svc.SFTP.Connect()
 			if err != nil {
 				return fmt.Errorf("failed to connect to SFTP server: %w", err)
@@ -128,7 +154,7 @@ func New() *cobra.Command {
 				remoteFolders = viper.GetStringSlice(config.KeySCPDestTVShowsPaths)
 			}
 
-			err = setRemoteDiskUsageStats(remoteFolders)
+			err = setRemoteDiskUsages(remoteFolders)
 			if err != nil {
 				return err
 			}
@@ -164,6 +190,16 @@ func New() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(&delete, "delete", "d", false, "remove source files after upload")
 	cmd.PersistentFlags().IntVarP(&maxParallel, "max-parallel", "p", 1, "maximum number of parallel processes. 0 means no limit")
 	cmd.PersistentFlags().BoolVarP(&recursive, "recursive", "r", false, "find files and folders recursively")
+	cmd.PersistentFlags().BoolVar(&refresh, "refresh", true, "trigger a Plex scan of uploaded folders")
+	cmd.PersistentFlags().BoolVar(&resume, "resume", true, "skip files already uploaded during a previous run")
+	cmd.PersistentFlags().Var(
+		enumflag.New(&transport, "transport", uploader.TransportIDs, enumflag.EnumCaseInsensitive),
+		"transport",
+		"transfer backend: rsync|sftp",
+	)
+	cmd.RegisterFlagCompletionFunc("transport", transportCompletion)
+	cmd.PersistentFlags().BoolVar(&upgrade, "upgrade", false, "replace existing releases, moving them to the remote trash folder")
+	cmd.PersistentFlags().BoolVar(&verify, "verify", true, "verify remote files checksum after upload")
 	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "automatic yes to prompts")
 	cmd.AddCommand(newAnimeCmd())
 	cmd.AddCommand(newMovieCmd())
@@ -172,70 +208,77 @@ func New() *cobra.Command {
 	return cmd
 }
 
-func setRemoteDiskUsageStats(paths []string) error {
-	remoteDiskUsageStats = make(map[string]int, len(paths))
-	raw, err := svc.SFTP.SendCommands("zpool list")
+func newPrompter() prompt.Prompter {
+	if yes {
+		return prompt.NewAuto()
+	}
+	return prompt.NewInteractive()
+}
+
+func transportCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{
+		"rsync\tuse Rsync to transfer files",
+		"sftp\tuse the built-in SFTP client to transfer files",
+	}, cobra.ShellCompDirectiveDefault
+}
+
+func setRemoteDiskUsages(paths []string) error {
+	client, err := svc.SFTP.Client()
 	if err != nil {
-		return fmt.Errorf("unable to get remote disk usage: %v", err)
+		return err
 	}
 
-	lowestUsage := 100
-	for _, path := range paths {
-		usage, err := getDiskUsage(string(raw), path)
-		if err != nil {
-			return err
-		}
-		remoteDiskUsageStats[path] = usage
-		if usage < lowestUsage {
-			remoteDirWithLowestUsage = path
-			lowestUsage = usage
-		}
+	provider, err := diskusage.New(
+		viper.GetString(config.KeySCPDiskUsageProvider),
+		svc.SSH.SendCommands,
+		client,
+	)
+	if err != nil {
+		return err
+	}
+
+	remoteDiskUsages, err = provider.Usage(paths)
+	if err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func getDiskUsage(str, path string) (percentage int, err error) {
-	found := false
-	lines := strings.SplitSeq(str, "\n")
-	for line := range lines {
-		usageRegexp := regexp.MustCompile(`(?P<Pool>[a-zA-Z0-9-_]+)(?:\s+.+)(?:\d+%\s+)(?P<Percentage>\d+)(?:%)(?:.+)$`)
-
-		allMatches := usageRegexp.FindAllStringSubmatch(line, -1)
-		if len(allMatches) == 0 {
+// Plans the distribution of given items across the remote folders depending on their free space.
+func planDestinations(items []planner.Item) (*planner.Plan, error) {
+	destinations := make([]planner.Destination, 0, len(remoteFolders))
+	for _, path := range remoteFolders {
+		usage, ok := remoteDiskUsages[path]
+		if !ok {
 			continue
 		}
+		destinations = append(destinations, planner.Destination{
+			Path:       filepath.Clean(path),
+			Free:       usage.Free,
+			Filesystem: usage.Filesystem,
+		})
+	}
 
-		matches := allMatches[len(allMatches)-1]
+	plan, err := planner.New(items, destinations)
+	if err != nil {
+		return nil, fmt.Errorf("could not plan upload: %w", err)
+	}
 
-		if len(matches) != 3 {
-			continue
-		}
+	return plan, nil
+}
 
-		poolIndex := usageRegexp.SubexpIndex("Pool")
-		if poolIndex == -1 {
-			return 0, fmt.Errorf("could not determine pool")
-		}
-		pool := matches[poolIndex]
-		if strings.Contains(path, pool) {
-			found = true
-			percentageIndex := usageRegexp.SubexpIndex("Percentage")
-			if percentageIndex == -1 {
-				return 0, fmt.Errorf("could not determine usage percentage")
-			}
-			percentage, err = strconv.Atoi(matches[percentageIndex])
-			if err != nil {
-				return 0, fmt.Errorf("could not parse usage percentage: %w", err)
-			}
-			break
+// Returns the total size of given local files.
+func localFilesSize(files []media.MediaFile) (int64, error) {
+	var size int64
+	for _, file := range files {
+		info, err := os.Stat(file.FilePath())
+		if err != nil {
+			return 0, fmt.Errorf("could not read %s information: %w", file.Basename(), err)
 		}
+		size += info.Size()
 	}
-
-	if !found {
-		return 0, fmt.Errorf("could not find pool for path %s", path)
-	}
-
-	return percentage, nil
+	return size, nil
 }
 
 type upload struct {
@@ -243,9 +286,33 @@ type upload struct {
 	DisplayName string
 	File        media.MediaFile
 	ImageFiles  []*image.Image
+
+	// Remote files removed once the upload succeeded, e.g. a previous release of a replaced movie. They are moved to
+	// the remote trash folder instead when --upgrade is set.
+	Replaces []string
 }
 
-func process(ctx context.Context, out io.Writer, uploads []*upload, kind media.Kind) error {
+func process(ctx context.Context, out io.Writer, uploads []*upload, kind media.Kind, plan *planner.Plan) error {
+	uploadJournal, err := journal.Open(journal.DefaultPath())
+	if err != nil {
+		return err
+	}
+
+	if resume {
+		var skipped int
+		uploads, skipped, err = withoutFinishedUploads(uploads, uploadJournal)
+		if err != nil {
+			return err
+		}
+		if skipped > 0 {
+			pterm.Info.Printfln("Skipping %d file%s already uploaded", skipped, lo.Ternary(skipped > 1, "s", ""))
+		}
+		if len(uploads) == 0 {
+			pterm.Success.Println("Nothing to upload")
+			return nil
+		}
+	}
+
 	pw := cmdutil.NewProgressWriter(out, len(uploads))
 
 	eg, _ := errgroup.WithContext(ctx)
@@ -261,14 +328,23 @@ func process(ctx context.Context, out io.Writer, uploads []*upload, kind media.K
 	})
 
 	printUploads(out, uploadsGroupedByDirName, kind)
+	printPlan(out, plan)
 
-	var p prompt.Prompter
-	if yes {
-		p = prompt.NewAuto()
-	} else {
-		p = prompt.NewInteractive()
+	if upgrade {
+		candidates, err := listUpgradeCandidates(ctx, uploads)
+		if err != nil {
+			return err
+		}
+		if len(candidates) > 0 {
+			fmt.Fprintln(out)
+			if err := printUpgrades(out, candidates); err != nil {
+				return err
+			}
+		}
 	}
 
+	p := newPrompter()
+
 	if !yes {
 		fmt.Fprintln(out)
 		shouldProcess, err := p.Confirm("Process?", true)
@@ -316,6 +392,12 @@ func process(ctx context.Context, out io.Writer, uploads []*upload, kind media.K
 		permissionsDepth = 1
 	}
 
+	// Each run gets its own trash folder so that releases replaced several times do not collide.
+	var trashDir string
+	if upgrade {
+		trashDir = filepath.Join(viper.GetString(config.KeySCPTrashPath), time.Now().Format("20060102-150405"))
+	}
+
 	uploaders := make([]svc.Runnable, len(uploads))
 	for index, upload := range uploads {
 		paddingLength := padder.PaddingLength(upload.DisplayName, 1)
@@ -324,17 +406,29 @@ func process(ctx context.Context, out io.Writer, uploads []*upload, kind media.K
 			Message:    fmt.Sprintf("%s%*s", upload.DisplayName, paddingLength, " "),
 			Total:      100,
 		}
+		// Rsync only reports percentages while SFTP transfers are tracked byte by byte. Checksums and permissions come
+		// after the last byte, so the tracker must not stop by itself once it is reached.
+		if transport == uploader.TransportSFTP {
+			info, err := os.Stat(upload.File.FilePath())
+			if err != nil {
+				return fmt.Errorf("could not read %s information: %w", upload.File.Basename(), err)
+			}
+			tracker.Total = info.Size()
+			tracker.Units = progress.UnitsBytes
+			tracker.AutoStopDisabled = true
+		}
 		pw.AppendTracker(tracker)
 
 		u := uploader.
-			New(kind, upload.File, upload.ImageFiles, upload.Destination, !delete, permissionsDepth).
+			New(kind, upload.File, upload.ImageFiles, upload.Destination, !delete, permissionsDepth, uploadJournal, verify, transport).
 			SetOutput(out).
 			SetTracker(tracker)
 		uploaders[index] = u
 	}
-	for _, uploader := range uploaders {
+	for index, uploader := range uploaders {
+		u := uploads[index]
 		eg.Go(func() error {
-			return uploader.Run(ctx)
+			return runReplacing(ctx, uploader, u, trashDir)
 		})
 	}
 	if err := eg.Wait(); err != nil {
@@ -348,9 +442,134 @@ func process(ctx context.Context, out io.Writer, uploads []*upload, kind media.K
 		time.Sleep(100 * time.Millisecond)
 	}
 
+	if refresh {
+		refreshLibrary(ctx, kind, uploads)
+	}
+
 	return nil
 }
 
+// Runs given upload, removing the files it replaces once it succeeded, or moving them to given trash folder when
+// --upgrade is set. A previous release stored at the same path is moved aside meanwhile, so that it is not mistaken for
+// a partially uploaded file and is put back if the upload fails.
+func runReplacing(ctx context.Context, uploader svc.Runnable, u *upload, trashDir string) error {
+	var aside *remote.AsideFile
+	if slices.Contains(u.Replaces, u.Destination) {
+		var err error
+		aside, err = remote.SetAside(u.Destination)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := uploader.Run(ctx); err != nil {
+		if aside != nil {
+			return errors.Join(err, aside.Restore())
+		}
+		return err
+	}
+
+	others := lo.Without(u.Replaces, u.Destination)
+	if upgrade {
+		if aside != nil {
+			if err := aside.MoveToTrash(trashDir); err != nil {
+				return err
+			}
+		}
+		return remote.MoveToTrash(trashDir, others...)
+	}
+
+	if aside != nil {
+		if err := aside.Remove(); err != nil {
+			return err
+		}
+	}
+	return removeReplacedFiles(others)
+}
+
+func removeReplacedFiles(paths []string) error {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := client.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove replaced file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Asks Plex to scan the folders that received new files. Failures are only reported as the upload itself succeeded.
+func refreshLibrary(ctx context.Context, kind media.Kind, uploads []*upload) {
+	if viper.GetString(config.KeyPlexAPIURL) == "" || viper.GetString(config.KeyPlexAPIToken) == "" {
+		pterm.Info.Println("Skipping Plex scan as Plex API is not configured")
+		return
+	}
+
+	var libraryKind plex.LibraryKind
+	var depth int
+	switch kind {
+	case media.KindAnime:
+		libraryKind, depth = plex.LibraryKindAnimes, 2
+	case media.KindMovie:
+		libraryKind, depth = plex.LibraryKindMovies, 1
+	case media.KindTVShow:
+		libraryKind, depth = plex.LibraryKindTVShows, 2
+	}
+
+	// Scan the movie or show folder rather than each file's parent folder to limit the number of requests.
+	paths := lo.Uniq(lo.Map(uploads, func(u *upload, _ int) string {
+		path := u.Destination
+		for range depth {
+			path = filepath.Dir(path)
+		}
+		return path
+	}))
+	slices.Sort(paths)
+
+	plexService := plex.NewServiceFromConfig()
+	libraryID, err := plexService.LibraryID(ctx, libraryKind)
+	if err == nil {
+		err = plexService.Refresh(ctx, libraryID, paths...)
+	}
+	if err != nil {
+		pterm.Warning.Printfln("Could not trigger Plex scan: %s", err)
+		return
+	}
+
+	pterm.Success.Printfln("Triggered Plex scan of %d folder%s", len(paths), lo.Ternary(len(paths) > 1, "s", ""))
+}
+
+// Filters out uploads already completed during a previous run, as long as neither the local nor the remote file
+// changed since then.
+func withoutFinishedUploads(
+	uploads []*upload,
+	uploadJournal *journal.Journal,
+) (remaining []*upload, skipped int, err error) {
+	client, err := svc.SFTP.Client()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, u := range uploads {
+		entry := uploadJournal.Get(u.File.FilePath(), u.Destination)
+		isFinished := entry != nil &&
+			(entry.Status == journal.StatusVerified || (entry.Status == journal.StatusUploaded && !verify))
+		if isFinished {
+			localInfo, localErr := os.Stat(u.File.FilePath())
+			remoteInfo, remoteErr := client.Stat(u.Destination)
+			if localErr == nil && remoteErr == nil && entry.Matches(localInfo) && remoteInfo.Size() == entry.Size {
+				skipped++
+				continue
+			}
+		}
+		remaining = append(remaining, u)
+	}
+	return remaining, skipped, nil
+}
+
 func printUploads(out io.Writer, uploadsGroupedByDirName map[string][]*upload, kind media.Kind) {
 	lw := cmdutil.NewListWriter()
 	for _, remoteDirName := range slices.Sorted(maps.Keys(uploadsGroupedByDirName)) {
@@ -379,12 +598,34 @@ func printUploads(out io.Writer, uploadsGroupedByDirName map[string][]*upload, k
 				pterm.Gray(upload.File.Basename()),
 				upload.Destination),
 			)
+			for _, replaced := range upload.Replaces {
+				lw.AppendItem(fmt.Sprintf("%s %s", pterm.Red("replaces"), replaced))
+			}
 		}
 		lw.UnIndent()
 	}
 	fmt.Fprintln(out, lw.Render())
 }
 
+func printPlan(out io.Writer, plan *planner.Plan) {
+	lw := cmdutil.NewListWriter()
+	lw.AppendItem("Planned distribution")
+	lw.Indent()
+	for _, destination := range plan.Destinations {
+		lw.AppendItem(fmt.Sprintf("%s  %s",
+			destination.Path,
+			pterm.Gray(fmt.Sprintf("(%d item%s, %s, %s free after upload)",
+				len(destination.Items),
+				lo.Ternary(len(destination.Items) > 1, "s", ""),
+				util.FormatBytes(destination.Planned),
+				util.FormatBytes(int64(destination.Remaining())),
+			)),
+		))
+	}
+	lw.UnIndent()
+	fmt.Fprintln(out, lw.Render())
+}
+
 func toShortName(remoteDirName string, from int) string {
 	parts := strings.Split(remoteDirName, string(filepath.Separator))
 	return strings.Join(parts[len(parts)-from:], string(filepath.Separator))
@@ -0,0 +1,404 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/internal/remote"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/diskusage"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/journal"
+	"github.com/jeremiergz/nas-cli/internal/cmd/media/library/upload/internal/probe"
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	"github.com/jeremiergz/nas-cli/internal/media"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+	"github.com/jeremiergz/nas-cli/internal/util"
+)
+
+func Test_Set_Remote_Disk_Usages_From_Server(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.HandleCommand("zpool list", func(cmd string, stdout, stderr io.Writer) int {
+		fmt.Fprint(stdout, "volume1\t1000\t250\t750\nvolume2\t2000\t1500\t500\n")
+		return 0
+	})
+	configtest.Set(t, map[string]any{config.KeySCPDiskUsageProvider: diskusage.ProviderZFS})
+	t.Cleanup(func() { remoteDiskUsages = nil })
+
+	require.NoError(t, setRemoteDiskUsages([]string{"/volume1/movies", "/volume2/movies"}))
+
+	assert.Equal(t, &diskusage.Usage{Path: "/volume1/movies", Total: 1000, Used: 250, Free: 750, Filesystem: "volume1"}, remoteDiskUsages["/volume1/movies"])
+	assert.Equal(t, &diskusage.Usage{Path: "/volume2/movies", Total: 2000, Used: 1500, Free: 500, Filesystem: "volume2"}, remoteDiskUsages["/volume2/movies"])
+	assert.Equal(t, []string{"zpool list -Hp -o name,size,alloc,free"}, server.Commands())
+}
+
+func Test_Set_Remote_Disk_Usages_Fails_On_Command_Error(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.HandleCommand("zpool list", func(cmd string, stdout, stderr io.Writer) int {
+		fmt.Fprintln(stderr, "no pools available")
+		return 1
+	})
+	configtest.Set(t, map[string]any{config.KeySCPDiskUsageProvider: diskusage.ProviderZFS})
+
+	err := setRemoteDiskUsages([]string{"/volume1/movies"})
+
+	assert.ErrorContains(t, err, "unable to get remote disk usage")
+	assert.ErrorContains(t, err, "no pools available")
+}
+
+func Test_Without_Finished_Uploads_Checks_Remote_Files(t *testing.T) {
+	server := servicetest.NewServer(t)
+	require.NoError(t, svc.SFTP.Connect())
+	t.Cleanup(func() { verify = true })
+	verify = true
+
+	wd := t.TempDir()
+	for _, name := range []string{"Finished (2020).mkv", "Truncated (2021).mkv", "New (2022).mkv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(wd, name), []byte("content"), 0o644))
+	}
+	movies, err := media.ListMovies(wd, []string{util.ExtensionMKV}, false)
+	require.NoError(t, err)
+
+	uploadJournal, err := journal.Open(filepath.Join(t.TempDir(), "journal.json"))
+	require.NoError(t, err)
+
+	var uploads []*upload
+	for _, movie := range movies {
+		u := &upload{
+			Destination: filepath.Join("/volume1/movies", movie.Name(), movie.Basename()),
+			DisplayName: movie.Basename(),
+			File:        movie,
+		}
+		uploads = append(uploads, u)
+
+		if movie.Name() == "New" {
+			continue
+		}
+		info, err := os.Stat(movie.FilePath())
+		require.NoError(t, err)
+		require.NoError(t, uploadJournal.Put(&journal.Entry{
+			Source:      movie.FilePath(),
+			Destination: u.Destination,
+			Size:        info.Size(),
+			ModTime:     info.ModTime(),
+			Status:      journal.StatusVerified,
+		}))
+		content := "content"
+		if movie.Name() == "Truncated" {
+			content = "cont"
+		}
+		server.WriteFile(t, u.Destination, content)
+	}
+
+	remaining, skipped, err := withoutFinishedUploads(uploads, uploadJournal)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, skipped)
+	names := []string{}
+	for _, u := range remaining {
+		names = append(names, u.File.Name())
+	}
+	assert.ElementsMatch(t, []string{"Truncated", "New"}, names)
+}
+
+func Test_Find_Existing_Movies_Across_Destinations(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.MkdirAll(t, "/volume1/movies/Amelie (2001)", "/volume2/movies/Other (2020)")
+	configtest.Set(t, map[string]any{config.KeySCPDestMoviesPaths: []string{"/volume1/movies", "/volume2/movies"}})
+	require.NoError(t, svc.SFTP.Connect())
+
+	wd := t.TempDir()
+	for _, name := range []string{"Amélie (2001).mkv", "New (2022).mkv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(wd, name), []byte("content"), 0o644))
+	}
+	movies, err := media.ListMovies(wd, []string{util.ExtensionMKV}, false)
+	require.NoError(t, err)
+
+	existing, err := findExistingMovies(movies)
+
+	require.NoError(t, err)
+	require.Len(t, existing, 1)
+	require.Len(t, existing["Amélie (2001)"], 1)
+	assert.Equal(t, "/volume1/movies/Amelie (2001)", existing["Amélie (2001)"][0].Path())
+}
+
+// Prompter answering selections with given choice, or the default one when empty.
+type selectPrompter struct {
+	choice        string
+	defaultChoice string
+}
+
+func (p *selectPrompter) Confirm(_ string, defaultValue bool) (bool, error) { return defaultValue, nil }
+
+func (p *selectPrompter) Input(_, defaultValue string) (string, error) { return defaultValue, nil }
+
+func (p *selectPrompter) Password(label string) (string, error) {
+	return "", fmt.Errorf("unexpected prompt %q", label)
+}
+
+func (p *selectPrompter) Select(_ string, _ []string, defaultValue string) (string, error) {
+	p.defaultChoice = defaultValue
+	if p.choice != "" {
+		return p.choice, nil
+	}
+	return defaultValue, nil
+}
+
+// Stubs probes so that remote releases are 720p while the local one has given details, or cannot be probed if nil.
+func stubProbes(t *testing.T, localDetails *probe.Details) {
+	t.Helper()
+
+	previousProbeLocal, previousProbeRemote := probeLocal, probeRemote
+	t.Cleanup(func() { probeLocal, probeRemote = previousProbeLocal, previousProbeRemote })
+	probeLocal = func(_ context.Context, path string) (*probe.Details, error) {
+		if localDetails == nil {
+			return nil, fmt.Errorf("could not probe %s", path)
+		}
+		return localDetails, nil
+	}
+	probeRemote = func(_ string) (*probe.Details, error) {
+		return &probe.Details{Width: 1280, Height: 720, Codec: "h264", BitRate: 2_000_000}, nil
+	}
+}
+
+func setupExistingMovie(t *testing.T, localDetails *probe.Details) *media.Movie {
+	t.Helper()
+
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "old release")
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).en.srt", "subtitles")
+	require.NoError(t, svc.SFTP.Connect())
+	stubProbes(t, localDetails)
+
+	wd := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "Movie (2024).mkv"), []byte("new release"), 0o644))
+	movies, err := media.ListMovies(wd, []string{util.ExtensionMKV}, false)
+	require.NoError(t, err)
+
+	return movies[0]
+}
+
+func Test_Resolve_Existing_Movie_Suggests_Replacing_Lower_Resolution(t *testing.T) {
+	movie := setupExistingMovie(t, &probe.Details{Width: 1920, Height: 1080, Codec: "hevc", BitRate: 3_000_000})
+	p := &selectPrompter{}
+	var out bytes.Buffer
+
+	toReplace, skip, err := resolveExistingMovie(context.Background(), &out, p, movie, []*remote.Item{{Root: "/volume1/movies", Name: "Movie (2024)"}})
+
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "/volume1/movies/Movie (2024)/Movie (2024).mkv")
+	assert.Contains(t, out.String(), "1280x720")
+	assert.Contains(t, out.String(), "1920x1080")
+	assert.Contains(t, out.String(), "3.0 Mb/s")
+	assert.False(t, skip)
+	assert.Equal(t, "replace /volume1/movies/Movie (2024)", p.defaultChoice)
+	require.NotNil(t, toReplace)
+	assert.Equal(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", toReplace.destination(movie))
+	assert.Equal(t, []string{"/volume1/movies/Movie (2024)/Movie (2024).mkv"}, toReplace.replacedFiles())
+}
+
+func Test_Resolve_Existing_Movie_Suggests_Skipping_Unknown_Resolution(t *testing.T) {
+	movie := setupExistingMovie(t, nil)
+	items := []*remote.Item{{Root: "/volume1/movies", Name: "Movie (2024)"}}
+
+	p := &selectPrompter{}
+	toReplace, skip, err := resolveExistingMovie(context.Background(), io.Discard, p, movie, items)
+	require.NoError(t, err)
+	assert.Equal(t, existingMovieSkip, p.defaultChoice)
+	assert.True(t, skip)
+	assert.Nil(t, toReplace)
+
+	toReplace, skip, err = resolveExistingMovie(context.Background(), io.Discard, &selectPrompter{choice: existingMovieKeepBoth}, movie, items)
+	require.NoError(t, err)
+	assert.False(t, skip)
+	assert.Nil(t, toReplace)
+}
+
+func Test_Resolve_Existing_Movie_Suggests_Skipping_Same_Release(t *testing.T) {
+	movie := setupExistingMovie(t, &probe.Details{Width: 1280, Height: 720, Codec: "h264", BitRate: 2_000_000})
+	p := &selectPrompter{}
+
+	_, skip, err := resolveExistingMovie(context.Background(), io.Discard, p, movie, []*remote.Item{{Root: "/volume1/movies", Name: "Movie (2024)"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, existingMovieSkip, p.defaultChoice)
+	assert.True(t, skip)
+}
+
+// Uploader writing given content to the destination before returning given error.
+type fakeUploader struct {
+	t           *testing.T
+	server      *servicetest.Server
+	destination string
+	content     string
+	err         error
+}
+
+func (f *fakeUploader) Run(_ context.Context) error {
+	f.server.WriteFile(f.t, f.destination, f.content)
+	return f.err
+}
+
+func (f *fakeUploader) SetOutput(_ io.Writer) svc.Runnable { return f }
+
+func (f *fakeUploader) SetTracker(_ *progress.Tracker) svc.Runnable { return f }
+
+func Test_Run_Replacing_Restores_Previous_Release_On_Failure(t *testing.T) {
+	server := servicetest.NewServer(t)
+	destination := "/volume1/movies/Movie (2024)/Movie (2024).mkv"
+	server.WriteFile(t, destination, "old release")
+	u := &upload{Destination: destination, Replaces: []string{destination}}
+
+	err := runReplacing(context.Background(), &fakeUploader{t, server, destination, "partial", errors.New("connection lost")}, u, "")
+
+	assert.ErrorContains(t, err, "connection lost")
+	assert.Equal(t, "old release", server.ReadFile(t, destination))
+	assert.NoFileExists(t, server.LocalPath("/volume1/movies/Movie (2024)/.Movie (2024).mkv.replaced"))
+}
+
+func Test_Run_Replacing_Removes_Previous_Releases_Once_Uploaded(t *testing.T) {
+	server := servicetest.NewServer(t)
+	destination := "/volume1/movies/Movie (2024)/Movie (2024).mkv"
+	other := "/volume1/movies/Movie (2024)/Movie (2024) - 720p.mkv"
+	server.WriteFile(t, destination, "old release")
+	server.WriteFile(t, other, "other release")
+	u := &upload{Destination: destination, Replaces: []string{destination, other}}
+
+	err := runReplacing(context.Background(), &fakeUploader{t, server, destination, "new release", nil}, u, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "new release", server.ReadFile(t, destination))
+	assert.NoFileExists(t, server.LocalPath(other))
+	assert.NoFileExists(t, server.LocalPath("/volume1/movies/Movie (2024)/.Movie (2024).mkv.replaced"))
+}
+
+func Test_Run_Replacing_Trashes_Previous_Releases_Once_Uploaded(t *testing.T) {
+	server := servicetest.NewServer(t)
+	destination := "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv"
+	other := "/volume1/tvshows/Show/Season 1/Show - S01E01 - 720p.mkv"
+	server.WriteFile(t, destination, "old release")
+	server.WriteFile(t, other, "other release")
+	setUpgrade(t)
+	u := &upload{Destination: destination, Replaces: []string{destination, other}}
+
+	err := runReplacing(context.Background(), &fakeUploader{t, server, destination, "new release", nil}, u, "/trash/run")
+
+	require.NoError(t, err)
+	assert.Equal(t, "new release", server.ReadFile(t, destination))
+	assert.Equal(t, "old release", server.ReadFile(t, "/trash/run"+destination))
+	assert.Equal(t, "other release", server.ReadFile(t, "/trash/run"+other))
+	assert.NoFileExists(t, server.LocalPath(other))
+}
+
+func Test_Run_Replacing_Keeps_Previous_Releases_When_Upgrade_Fails(t *testing.T) {
+	server := servicetest.NewServer(t)
+	destination := "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv"
+	other := "/volume1/tvshows/Show/Season 1/Show - S01E01 - 720p.mkv"
+	server.WriteFile(t, destination, "old release")
+	server.WriteFile(t, other, "other release")
+	setUpgrade(t)
+	u := &upload{Destination: destination, Replaces: []string{destination, other}}
+
+	err := runReplacing(context.Background(), &fakeUploader{t, server, destination, "partial", errors.New("connection lost")}, u, "/trash/run")
+
+	assert.ErrorContains(t, err, "connection lost")
+	assert.Equal(t, "old release", server.ReadFile(t, destination))
+	assert.Equal(t, "other release", server.ReadFile(t, other))
+	assert.NoDirExists(t, server.LocalPath("/trash"))
+}
+
+func setUpgrade(t *testing.T) {
+	t.Helper()
+
+	previous := upgrade
+	upgrade = true
+	t.Cleanup(func() { upgrade = previous })
+}
+
+func setupUpgradedShow(t *testing.T) []*upload {
+	t.Helper()
+
+	wd := t.TempDir()
+	for _, name := range []string{"Show - S01E01.mkv", "Show - S01E02.mkv", "Show - S01E03.mkv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(wd, name), []byte("1080p"), 0o644))
+	}
+	shows, err := media.ListShows(wd, []string{util.ExtensionMKV}, false)
+	require.NoError(t, err)
+
+	var uploads []*upload
+	for _, season := range shows[0].Seasons() {
+		for _, episode := range season.Episodes() {
+			uploads = append(uploads, &upload{
+				File:        episode,
+				Destination: filepath.Join("/volume1/tvshows/Show", episode.Season().Name(), episode.FullName()),
+				DisplayName: episode.FullName(),
+			})
+		}
+	}
+	return uploads
+}
+
+func Test_Find_Replaced_Episodes_Matches_Season_And_Episode(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv", "720p")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.en.srt", "subtitles")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show.S01E02.720p.WEB.x264-GROUP.mkv", "720p")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E04.mkv", "720p")
+	require.NoError(t, svc.SFTP.Connect())
+	uploads := setupUpgradedShow(t)
+
+	require.NoError(t, findReplacedEpisodes(uploads))
+
+	replaces := map[string][]string{}
+	for _, u := range uploads {
+		replaces[u.DisplayName] = u.Replaces
+	}
+	assert.Equal(t, map[string][]string{
+		"Show - S01E01.mkv": {"/volume1/tvshows/Show/Season 1/Show - S01E01.mkv"},
+		"Show - S01E02.mkv": {"/volume1/tvshows/Show/Season 1/Show.S01E02.720p.WEB.x264-GROUP.mkv"},
+		"Show - S01E03.mkv": nil,
+	}, replaces)
+}
+
+func Test_Print_Upgrades_Compares_Probed_Releases(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E01.mkv", "720p")
+	server.WriteFile(t, "/volume1/tvshows/Show/Season 1/Show - S01E02.mkv", "1080p")
+	require.NoError(t, svc.SFTP.Connect())
+	previousProbeLocal, previousProbeRemote := probeLocal, probeRemote
+	t.Cleanup(func() { probeLocal, probeRemote = previousProbeLocal, previousProbeRemote })
+	probeLocal = func(_ context.Context, _ string) (*probe.Details, error) {
+		return &probe.Details{Width: 1920, Height: 1080, Codec: "hevc", BitRate: 3_000_000}, nil
+	}
+	probeRemote = func(path string) (*probe.Details, error) {
+		if filepath.Base(path) == "Show - S01E02.mkv" {
+			return nil, errors.New("ffprobe: command not found")
+		}
+		return &probe.Details{Width: 1280, Height: 720, Codec: "h264", BitRate: 2_000_000}, nil
+	}
+	uploads := setupUpgradedShow(t)
+	require.NoError(t, findReplacedEpisodes(uploads))
+
+	candidates, err := listUpgradeCandidates(context.Background(), uploads)
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.True(t, candidates[0].isUpgrade())
+	assert.Equal(t, int64(4), candidates[0].size)
+	assert.False(t, candidates[1].isUpgrade())
+
+	var out bytes.Buffer
+	require.NoError(t, printUpgrades(&out, candidates))
+	assert.Contains(t, out.String(), "1280x720")
+	assert.Contains(t, out.String(), "3.0 Mb/s")
+	assert.Contains(t, out.String(), "unknown")
+}
@@ -45,22 +45,39 @@ const (
 	// FileMode is the default mode to apply to files.
 	FileMode os.FileMode = 0644
 
-	KeyNASFQDN             string = "nas.fqdn"
-	KeyPlexAPIURL          string = "plex.api.url"
-	KeyPlexAPIToken        string = "plex.api.token"
-	KeySCPChownGID         string = "scp.chown.gid"
-	KeySCPChownGroup       string = "scp.chown.group"
-	KeySCPChownUID         string = "scp.chown.uid"
-	KeySCPChownUser        string = "scp.chown.user"
-	KeySCPDestAnimesPaths  string = "scp.dest.animespaths"
-	KeySCPDestMoviesPaths  string = "scp.dest.moviespaths"
-	KeySCPDestTVShowsPaths string = "scp.dest.tvshowspaths"
-	KeySSHClientKnownHosts string = "ssh.client.knownhosts"
-	KeySSHClientPrivateKey string = "ssh.client.privatekey"
-	KeySSHHost             string = "ssh.host"
-	KeySSHPort             string = "ssh.port"
-	KeySSHUser             string = "ssh.user"
-	KeySubsyncOptions      string = "subsync.options"
+	// DefaultSSHPort is the SSH port used unless configured otherwise.
+	DefaultSSHPort string = "22"
+
+	KeyNASFQDN              string = "nas.fqdn"
+	KeyPlexAPIClientID      string = "plex.api.clientid"
+	KeyPlexAPIInsecure      string = "plex.api.insecure"
+	KeyPlexAPITimeout       string = "plex.api.timeout"
+	KeyPlexAPIURL           string = "plex.api.url"
+	KeyPlexAPIToken         string = "plex.api.token"
+	KeyPlexSectionsAnimes   string = "plex.sections.animes"
+	KeyPlexSectionsMovies   string = "plex.sections.movies"
+	KeyPlexSectionsTVShows  string = "plex.sections.tvshows"
+	KeySCPChownGID          string = "scp.chown.gid"
+	KeySCPChownGroup        string = "scp.chown.group"
+	KeySCPChownUID          string = "scp.chown.uid"
+	KeySCPChownUser         string = "scp.chown.user"
+	KeySCPDestAnimesPaths   string = "scp.dest.animespaths"
+	KeySCPDestMoviesPaths   string = "scp.dest.moviespaths"
+	KeySCPDestTVShowsPaths  string = "scp.dest.tvshowspaths"
+	KeySCPDiskUsageProvider string = "scp.diskusage.provider"
+	KeySCPSFTPChunkSize     string = "scp.sftp.chunksize"
+	KeySCPSFTPParallelism   string = "scp.sftp.parallelism"
+	KeySCPTransport         string = "scp.transport"
+	KeySCPTrashPath         string = "scp.trashpath"
+	KeySSHClientAgent       string = "ssh.client.agent"
+	KeySSHClientConfig      string = "ssh.client.config"
+	KeySSHClientKnownHosts  string = "ssh.client.knownhosts"
+	KeySSHClientPrivateKey  string = "ssh.client.privatekey"
+	KeySSHHost              string = "ssh.host"
+	KeySSHJump              string = "ssh.jump"
+	KeySSHPort              string = "ssh.port"
+	KeySSHUser              string = "ssh.user"
+	KeySubsyncOptions       string = "subsync.options"
 )
 
 var (
@@ -78,6 +95,12 @@ var (
 		KeyNASFQDN,
 		KeyPlexAPIURL,
 		KeyPlexAPIToken,
+		KeyPlexAPIClientID,
+		KeyPlexAPIInsecure,
+		KeyPlexAPITimeout,
+		KeyPlexSectionsAnimes,
+		KeyPlexSectionsMovies,
+		KeyPlexSectionsTVShows,
 		KeySCPChownGID,
 		KeySCPChownGroup,
 		KeySCPChownUID,
@@ -85,9 +108,17 @@ var (
 		KeySCPDestAnimesPaths,
 		KeySCPDestMoviesPaths,
 		KeySCPDestTVShowsPaths,
+		KeySCPDiskUsageProvider,
+		KeySCPSFTPChunkSize,
+		KeySCPSFTPParallelism,
+		KeySCPTransport,
+		KeySCPTrashPath,
 		KeySSHHost,
 		KeySSHPort,
 		KeySSHUser,
+		KeySSHJump,
+		KeySSHClientAgent,
+		KeySSHClientConfig,
 		KeySSHClientKnownHosts,
 		KeySSHClientPrivateKey,
 		KeySubsyncOptions,
@@ -118,6 +149,12 @@ func init() {
 
 		viper.SetDefault(KeyPlexAPIURL, "https://localhost:32400")
 		viper.SetDefault(KeyPlexAPIToken, "")
+		viper.SetDefault(KeyPlexAPIClientID, "")
+		viper.SetDefault(KeyPlexAPIInsecure, false)
+		viper.SetDefault(KeyPlexAPITimeout, "30s")
+		viper.SetDefault(KeyPlexSectionsAnimes, "")
+		viper.SetDefault(KeyPlexSectionsMovies, "")
+		viper.SetDefault(KeyPlexSectionsTVShows, "")
 
 		viper.SetDefault(KeySCPChownGID, 1000)
 		viper.SetDefault(KeySCPChownGroup, "media")
@@ -128,6 +165,13 @@ func init() {
 		viper.SetDefault(KeySCPDestMoviesPaths, []string{})
 		viper.SetDefault(KeySCPDestTVShowsPaths, []string{})
 
+		viper.SetDefault(KeySCPDiskUsageProvider, "zfs")
+
+		viper.SetDefault(KeySCPSFTPChunkSize, 4*1024*1024)
+		viper.SetDefault(KeySCPSFTPParallelism, 4)
+		viper.SetDefault(KeySCPTransport, "rsync")
+		viper.SetDefault(KeySCPTrashPath, "")
+
 		sshHost := viper.GetString(KeySSHHost)
 		viper.SetDefault(KeySSHHost, "localhost")
 		if nasDomain != "" && sshHost == "" {
@@ -156,16 +200,19 @@ func init() {
 			viper.SetDefault(KeySSHClientPrivateKey, defaultPrivateKey)
 		}
 
-		viper.SetDefault(KeySSHPort, "22")
-
-		currentUser, _ := user.Current()
-		var defaultUsername string
-		if currentUser != nil {
-			defaultUsername = currentUser.Username
+		sshConfig := viper.GetString(KeySSHClientConfig)
+		if sshConfig != "" && strings.HasPrefix(sshConfig, tildeStr) {
+			sshConfig = strings.Replace(sshConfig, tildeStr, homedir+sep, 1)
+			viper.Set(KeySSHClientConfig, sshConfig)
 		} else {
-			defaultUsername = os.Getenv("USER")
+			defaultConfig := filepath.Join(homedir, ".ssh", "config")
+			viper.SetDefault(KeySSHClientConfig, defaultConfig)
 		}
-		viper.SetDefault(KeySSHUser, defaultUsername)
+
+		viper.SetDefault(KeySSHClientAgent, true)
+		viper.SetDefault(KeySSHJump, "")
+		viper.SetDefault(KeySSHPort, DefaultSSHPort)
+		viper.SetDefault(KeySSHUser, DefaultSSHUser())
 
 		viper.SetDefault(KeySubsyncOptions, []string{})
 
@@ -189,15 +236,28 @@ type (
 		FQDN string `yaml:"fqdn"`
 	}
 	Plex struct {
-		API PlexAPI `yaml:"api"`
+		API      PlexAPI      `yaml:"api"`
+		Sections PlexSections `yaml:"sections"`
 	}
 	PlexAPI struct {
-		URL   string `yaml:"url"`
-		Token string `yaml:"token"`
+		URL      string `yaml:"url"`
+		Token    string `yaml:"token"`
+		ClientID string `yaml:"clientid"`
+		Insecure bool   `yaml:"insecure"`
+		Timeout  string `yaml:"timeout"`
+	}
+	PlexSections struct {
+		Animes  string `yaml:"animes"`
+		Movies  string `yaml:"movies"`
+		TVShows string `yaml:"tvshows"`
 	}
 	SCP struct {
-		Chown Chown `yaml:"chown"`
-		Dest  Dest  `yaml:"dest"`
+		Chown     Chown     `yaml:"chown"`
+		Dest      Dest      `yaml:"dest"`
+		DiskUsage DiskUsage `yaml:"diskusage"`
+		SFTP      SCPSFTP   `yaml:"sftp"`
+		Transport string    `yaml:"transport"`
+		TrashPath string    `yaml:"trashpath"`
 	}
 	Chown struct {
 		UID   int    `yaml:"uid"`
@@ -210,13 +270,23 @@ type (
 		MoviesPaths  []string `yaml:"moviespaths"`
 		TVShowsPaths []string `yaml:"tvshowspaths"`
 	}
+	DiskUsage struct {
+		Provider string `yaml:"provider"`
+	}
+	SCPSFTP struct {
+		ChunkSize   int `yaml:"chunksize"`
+		Parallelism int `yaml:"parallelism"`
+	}
 	SSH struct {
 		Host   string `yaml:"host"`
 		Port   int    `yaml:"port"`
 		User   string `yaml:"user"`
+		Jump   string `yaml:"jump"`
 		Client Client `yaml:"client"`
 	}
 	Client struct {
+		Agent      bool   `yaml:"agent"`
+		Config     string `yaml:"config"`
 		KnownHosts string `yaml:"knownhosts"`
 		PrivateKey string `yaml:"privatekey"`
 	}
@@ -232,8 +302,16 @@ func Save() error {
 		},
 		Plex: Plex{
 			API: PlexAPI{
-				URL:   viper.GetString(KeyPlexAPIURL),
-				Token: viper.GetString(KeyPlexAPIToken),
+				URL:      viper.GetString(KeyPlexAPIURL),
+				Token:    viper.GetString(KeyPlexAPIToken),
+				ClientID: viper.GetString(KeyPlexAPIClientID),
+				Insecure: viper.GetBool(KeyPlexAPIInsecure),
+				Timeout:  viper.GetString(KeyPlexAPITimeout),
+			},
+			Sections: PlexSections{
+				Animes:  viper.GetString(KeyPlexSectionsAnimes),
+				Movies:  viper.GetString(KeyPlexSectionsMovies),
+				TVShows: viper.GetString(KeyPlexSectionsTVShows),
 			},
 		},
 		SCP: SCP{
@@ -248,12 +326,24 @@ func Save() error {
 				MoviesPaths:  viper.GetStringSlice(KeySCPDestMoviesPaths),
 				TVShowsPaths: viper.GetStringSlice(KeySCPDestTVShowsPaths),
 			},
+			DiskUsage: DiskUsage{
+				Provider: viper.GetString(KeySCPDiskUsageProvider),
+			},
+			SFTP: SCPSFTP{
+				ChunkSize:   viper.GetInt(KeySCPSFTPChunkSize),
+				Parallelism: viper.GetInt(KeySCPSFTPParallelism),
+			},
+			Transport: viper.GetString(KeySCPTransport),
+			TrashPath: viper.GetString(KeySCPTrashPath),
 		},
 		SSH: SSH{
 			Host: viper.GetString(KeySSHHost),
 			Port: viper.GetInt(KeySSHPort),
 			User: viper.GetString(KeySSHUser),
+			Jump: viper.GetString(KeySSHJump),
 			Client: Client{
+				Agent:      viper.GetBool(KeySSHClientAgent),
+				Config:     viper.GetString(KeySSHClientConfig),
 				KnownHosts: viper.GetString(KeySSHClientKnownHosts),
 				PrivateKey: viper.GetString(KeySSHClientPrivateKey),
 			},
@@ -279,3 +369,23 @@ func Save() error {
 
 	return nil
 }
+
+// DefaultSSHUser returns the SSH user used unless configured otherwise, which is the current user.
+func DefaultSSHUser() string {
+	currentUser, _ := user.Current()
+	if currentUser != nil {
+		return currentUser.Username
+	}
+	return os.Getenv("USER")
+}
+
+// IsConfigured returns whether given key is set in the configuration file or through the environment, rather than left
+// to its default, even when set to the same value as the default.
+func IsConfigured(key string) bool {
+	if viper.InConfig(key) {
+		return true
+	}
+	// Mirrors the variable name AutomaticEnv looks up.
+	_, ok := os.LookupEnv(strings.ToUpper(key))
+	return ok
+}
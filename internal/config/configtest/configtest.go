@@ -0,0 +1,19 @@
+// Package configtest provides helpers for tests depending on configuration entries.
+package configtest
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// Sets given configuration entries, restoring their previous values once the test is done.
+func Set(t testing.TB, values map[string]any) {
+	t.Helper()
+
+	for key, value := range values {
+		previous := viper.Get(key)
+		viper.Set(key, value)
+		t.Cleanup(func() { viper.Set(key, previous) })
+	}
+}
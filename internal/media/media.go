@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -236,6 +237,77 @@ func listBaseImageFiles(dir, referenceName string) (imageFiles []*image.Image, e
 	return imageFiles, nil
 }
 
+// Lists images in given folder grouped by the name they refer to, e.g. "Movie (2024)" for "Movie (2024).poster.jpg"
+// or "Show" for "Show.s01.jpg". Unlike images listed along with movies and shows, no media file is required.
+func ListImages(wd string) (map[string][]*image.Image, error) {
+	files, err := os.ReadDir(wd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	images := map[string][]*image.Image{}
+	for _, file := range files {
+		fileExtension := strings.ToLower(strings.TrimPrefix(filepath.Ext(file.Name()), "."))
+		if file.IsDir() || !slices.Contains(image.ValidExtensions, fileExtension) {
+			continue
+		}
+
+		filePath := filepath.Join(wd, file.Name())
+		fileName := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		referenceName, suffix, found := cutLast(fileName, ".")
+		if !found {
+			continue
+		}
+
+		switch {
+		case suffix == "background" || suffix == "bg":
+			images[referenceName] = append(images[referenceName], image.New("background", filePath, image.KindBackground))
+
+		case suffix == "poster" || suffix == "pt":
+			images[referenceName] = append(images[referenceName], image.New("poster", filePath, image.KindPoster))
+
+		case imageFileSeasonNumberRegexp.MatchString("." + suffix):
+			seasonNumber, err := strconv.Atoi(suffix[1:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse season number for %s: %w", fileName, err)
+			}
+			images[referenceName] = append(images[referenceName], image.New(SeasonImageName(seasonNumber), filePath, image.KindPoster))
+		}
+	}
+
+	return images, nil
+}
+
+// Returns the name of the poster image of given season, relative to the show folder.
+func SeasonImageName(seasonNumber int) string {
+	imageFileNamePrefix := lo.Ternary(
+		seasonNumber == 0,
+		"season-specials-poster",
+		fmt.Sprintf("Season%02d", seasonNumber),
+	)
+	return SeasonName(seasonNumber) + "/" + imageFileNamePrefix
+}
+
+// Returns the season number of given image name built with SeasonImageName.
+func ParseSeasonImageName(name string) (seasonNumber int, ok bool) {
+	seasonDir, _, found := strings.Cut(name, "/")
+	if !found {
+		return 0, false
+	}
+	seasonNumber, err := strconv.Atoi(strings.TrimPrefix(seasonDir, "Season "))
+	if err != nil {
+		return 0, false
+	}
+	return seasonNumber, true
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
 // Prints given files array as a tree.
 func PrintFiles(wd string, files []*File) {
 	lw := cmdutil.NewListWriter()
@@ -73,12 +73,7 @@ func (m *Movie) SetName(name string) {
 }
 
 func (m *Movie) FullName() string {
-	return fmt.Sprintf(
-		"%s (%d).%s",
-		m.Name(),
-		m.Year(),
-		m.Extension(),
-	)
+	return fmt.Sprintf("%s.%s", MovieName(m.Name(), m.Year()), m.Extension())
 }
 
 func (m *Movie) Year() int {
@@ -119,6 +114,38 @@ func PrintMovies(wd string, movies []*Movie) {
 
 var movieParsingRegexp = regexp.MustCompile(`^(?<name>.+)\s\((?<year>\d{4})\)\.(?<extension>.{3})$`)
 
+var movieNameRegexp = regexp.MustCompile(`^(?<name>.+)\s\((?<year>\d{4})\)$`)
+
+// Returns the name of given movie following the "<Title> (<Year>)" naming convention, used for its folder and file.
+func MovieName(title string, year int) string {
+	return fmt.Sprintf("%s (%d)", title, year)
+}
+
+// Parses given movie name following the "<Title> (<Year>)" naming convention.
+func ParseMovieName(name string) (title string, year int, err error) {
+	matches := movieNameRegexp.FindStringSubmatch(name)
+	if len(matches) != 3 {
+		return "", 0, errors.New("name does not match expected format")
+	}
+
+	title = matches[1]
+	year, _ = strconv.Atoi(matches[2])
+
+	return title, year, nil
+}
+
+// Guesses title and year of given movie file or folder name from its release name.
+func GuessMovie(name string) (title string, year int, err error) {
+	title, year, _, err = parseMovieWithParser(name)
+	if err != nil {
+		return "", 0, err
+	}
+	if title == "" || year == 0 {
+		return "", 0, fmt.Errorf("failed to parse movie %s: missing title or year", name)
+	}
+	return title, year, nil
+}
+
 func parseMovieWithRegexp(basename string) (name string, year int, extension string, err error) {
 	matches := movieParsingRegexp.FindStringSubmatch(basename)
 	if len(matches) != 4 {
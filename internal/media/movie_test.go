@@ -121,6 +121,33 @@ func TestParseMovies(t *testing.T) {
 	}
 }
 
+func TestParseMovieName(t *testing.T) {
+	title, year, err := ParseMovieName("The Matrix (1999)")
+	if err != nil {
+		t.Fatalf("ParseMovieName() error = %v", err)
+	}
+	if title != "The Matrix" || year != 1999 {
+		t.Errorf("ParseMovieName() = %q, %d, want %q, %d", title, year, "The Matrix", 1999)
+	}
+	if got := MovieName(title, year); got != "The Matrix (1999)" {
+		t.Errorf("MovieName() = %q, want %q", got, "The Matrix (1999)")
+	}
+
+	if _, _, err := ParseMovieName("The Matrix"); err == nil {
+		t.Error("ParseMovieName() expected error for name without year")
+	}
+}
+
+func TestGuessMovie(t *testing.T) {
+	title, year, err := GuessMovie("The.Matrix.1999.1080p.BluRay.x264")
+	if err != nil {
+		t.Fatalf("GuessMovie() error = %v", err)
+	}
+	if title != "The Matrix" || year != 1999 {
+		t.Errorf("GuessMovie() = %q, %d, want %q, %d", title, year, "The Matrix", 1999)
+	}
+}
+
 func must(f *file, err error) *file {
 	if err != nil {
 		panic(err)
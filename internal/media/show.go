@@ -110,13 +110,7 @@ func (e *Episode) Index() int {
 }
 
 func (e *Episode) Name() string {
-	width := max(numberOfDigits(e.Index()), 2)
-	return fmt.Sprintf("%s - S%02dE%0*d",
-		e.Season().Show().Name(),
-		e.Season().Index(),
-		width,
-		e.Index(),
-	)
+	return EpisodeName(e.Season().Show().Name(), e.Season().Index(), e.Index())
 }
 
 func (e *Episode) FullName() string {
@@ -134,6 +128,30 @@ func (e *Episode) PosterImageFilePath() *string {
 	return nil
 }
 
+// Returns the name of given episode following the "<Show> - SxxEyy" naming convention, without extension.
+func EpisodeName(showName string, seasonNumber, episodeNumber int) string {
+	width := max(numberOfDigits(episodeNumber), 2)
+	return fmt.Sprintf("%s - S%02dE%0*d", showName, seasonNumber, width, episodeNumber)
+}
+
+// Returns the name of the folder holding episodes of given season.
+func SeasonName(seasonNumber int) string {
+	return fmt.Sprintf("Season %d", seasonNumber)
+}
+
+var seasonNameRegexp = regexp.MustCompile(`(?i)^season\s*0*(\d+)$`)
+
+// Returns the season number of given folder name, also accepting names that do not follow the convention exactly
+// such as "season 01".
+func ParseSeasonName(name string) (seasonNumber int, ok bool) {
+	matches := seasonNameRegexp.FindStringSubmatch(name)
+	if matches == nil {
+		return 0, false
+	}
+	seasonNumber, _ = strconv.Atoi(matches[1])
+	return seasonNumber, true
+}
+
 // Finds season index in seasons array.
 func findShowSeasonIndex(name string, seasons []*Season) int {
 	seasonIndex := -1
@@ -192,13 +210,7 @@ func listSeasonImageFiles(dir, referenceName string) (imageFiles []*image.Image,
 				return nil, fmt.Errorf("failed to parse season number for %s: %w", fileName, err)
 			}
 
-			imageFileNamePrefix := lo.Ternary(
-				seasonNumber == 0,
-				"season-specials-poster",
-				fmt.Sprintf("Season%02d", seasonNumber),
-			)
-			imageFileName := fmt.Sprintf("Season %d/%s", seasonNumber, imageFileNamePrefix)
-			imageFiles = append(imageFiles, image.New(imageFileName, filePath, image.KindPoster))
+			imageFiles = append(imageFiles, image.New(SeasonImageName(seasonNumber), filePath, image.KindPoster))
 		}
 	}
 
@@ -266,6 +278,23 @@ func PrintShows(wd string, shows []*Show) {
 
 var showParsingRegexp = regexp.MustCompile(`^(?<name>.+)\s-\sS(?<season>\d{2})E(?<episode>\d{2,4})\.(?<extension>.{3})$`)
 
+// Parses given episode filename following the "<Show> - SxxEyy.<ext>" naming convention.
+func ParseEpisodeFilename(basename string) (name string, seasonNumber, episodeNumber int, extension string, err error) {
+	return parseShowWithRegexp(basename)
+}
+
+// Guesses show name, season and episode numbers of given episode filename from its release name.
+func GuessEpisode(basename string) (name string, seasonNumber, episodeNumber int, err error) {
+	name, seasonNumber, episodeNumber, _, err = parseShowWithParser(basename)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if name == "" || episodeNumber == 0 {
+		return "", 0, 0, fmt.Errorf("failed to parse show %s: missing name or episode number", basename)
+	}
+	return name, seasonNumber, episodeNumber, nil
+}
+
 func parseShowWithRegexp(basename string) (name string, seasonNumber, episodeNumber int, extension string, err error) {
 	matches := showParsingRegexp.FindStringSubmatch(basename)
 	if len(matches) != 5 {
@@ -328,7 +357,7 @@ func listShowsWithParser(
 		} else {
 			show = shows[showIndex]
 		}
-		seasonName := fmt.Sprintf("Season %d", seasonNumber)
+		seasonName := SeasonName(seasonNumber)
 		seasonIndex := findShowSeasonIndex(seasonName, show.Seasons())
 
 		f, err := newFile(basename, extension, filepath.Join(wd, path))
@@ -242,6 +242,32 @@ func TestSeasonName(t *testing.T) {
 	}
 }
 
+func TestParseSeasonName(t *testing.T) {
+	tests := map[string]int{"Season 2": 2, "season 02": 2, "Season10": 10}
+	for name, want := range tests {
+		got, ok := ParseSeasonName(name)
+		if !ok || got != want {
+			t.Errorf("ParseSeasonName(%q) = %d, %v, want %d, true", name, got, ok, want)
+		}
+	}
+	if _, ok := ParseSeasonName("Specials"); ok {
+		t.Error("ParseSeasonName() should not accept \"Specials\"")
+	}
+}
+
+func TestGuessEpisode(t *testing.T) {
+	name, season, episode, err := GuessEpisode("show.name.s01e02.720p.mkv")
+	if err != nil {
+		t.Fatalf("GuessEpisode() error = %v", err)
+	}
+	if name != "Show Name" || season != 1 || episode != 2 {
+		t.Errorf("GuessEpisode() = %q, %d, %d, want %q, 1, 2", name, season, episode, "Show Name")
+	}
+	if got := EpisodeName(name, season, episode); got != "Show Name - S01E02" {
+		t.Errorf("EpisodeName() = %q, want %q", got, "Show Name - S01E02")
+	}
+}
+
 func TestSeasonIndex(t *testing.T) {
 	show := &Show{name: "Test Show"}
 	season := &Season{name: "Season 2", index: 2, show: show}
@@ -17,6 +17,16 @@ type Prompter interface {
 	//   - Returns the entered string (or defaultValue if accepted as-is).
 	//   - Returns an error only on interrupt (^C).
 	Input(label, defaultValue string) (string, error)
+
+	// Asks the user for a secret value, masked while typed.
+	//   - Returns the entered string.
+	//   - Returns an error on interrupt (^C), or when there is no user to ask.
+	Password(label string) (string, error)
+
+	// Asks the user to pick one of given options.
+	//   - Returns the selected option (or defaultValue if accepted as-is).
+	//   - Returns an error only on interrupt (^C).
+	Select(label string, options []string, defaultValue string) (string, error)
 }
 
 // Uses promptui for real terminal interaction.
@@ -68,6 +78,43 @@ func (p *InteractivePrompter) Input(label, defaultValue string) (string, error)
 	return result, nil
 }
 
+func (p *InteractivePrompter) Password(label string) (string, error) {
+	isInterrupted := false
+	result, err := pterm.DefaultInteractiveTextInput.
+		WithDefaultText(label).
+		WithMask("*").
+		WithOnInterruptFunc(func() {
+			isInterrupted = true
+		}).
+		Show()
+	if isInterrupted {
+		return "", fmt.Errorf("interrupted")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to handle password: %w", err)
+	}
+	return result, nil
+}
+
+func (p *InteractivePrompter) Select(label string, options []string, defaultValue string) (string, error) {
+	isInterrupted := false
+	result, err := pterm.DefaultInteractiveSelect.
+		WithDefaultText(label).
+		WithOptions(options).
+		WithDefaultOption(defaultValue).
+		WithOnInterruptFunc(func() {
+			isInterrupted = true
+		}).
+		Show()
+	if isInterrupted {
+		return "", fmt.Errorf("interrupted")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to handle select: %w", err)
+	}
+	return result, nil
+}
+
 // Automatically confirms everything and accepts defaults.
 type AutoPrompter struct{}
 
@@ -83,3 +130,11 @@ func (p *AutoPrompter) Confirm(_ string, _ bool) (bool, error) {
 func (p *AutoPrompter) Input(_, defaultValue string) (string, error) {
 	return defaultValue, nil
 }
+
+func (p *AutoPrompter) Password(label string) (string, error) {
+	return "", fmt.Errorf("cannot ask for %q without prompting", label)
+}
+
+func (p *AutoPrompter) Select(_ string, _ []string, defaultValue string) (string, error) {
+	return defaultValue, nil
+}
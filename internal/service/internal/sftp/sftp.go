@@ -1,60 +1,88 @@
 package sftp
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
 
 	sshsvc "github.com/jeremiergz/nas-cli/internal/service/internal/ssh"
 )
 
+// SFTP session opened over the shared SSH connection.
 type Service struct {
-	Client *sftp.Client
-	ssh    *sshsvc.Service
+	mu        sync.Mutex
+	client    *sftp.Client
+	ssh       *sshsvc.Service
+	sshClient *ssh.Client
 }
 
-func New() *Service {
-	return &Service{}
+func New(sshSvc *sshsvc.Service) *Service {
+	return &Service{ssh: sshSvc}
 }
 
+// Opens an SFTP session unless one is already open over the current SSH connection, connecting first if needed.
 func (s *Service) Connect() error {
-	sshSvc := sshsvc.New()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connect()
+}
+
+// Returns the SFTP session, opening a new one first if never opened or if the SSH connection was dialed again since
+// it was opened.
+func (s *Service) Client() (*sftp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	err := sshSvc.Connect()
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s.client, nil
+}
+
+func (s *Service) connect() error {
+	sshClient, err := s.ssh.Client()
 	if err != nil {
 		return err
 	}
+	if s.client != nil && s.sshClient == sshClient {
+		return nil
+	}
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+		s.sshClient = nil
+	}
 
 	sftpClient, err := sftp.NewClient(
-		sshSvc.Client,
+		sshClient,
 		sftp.UseConcurrentReads(true),
 		sftp.UseConcurrentWrites(true),
 		sftp.MaxConcurrentRequestsPerFile(256),
 		sftp.MaxPacketUnchecked(128*1024),
 	)
-
 	if err != nil {
-		sshSvc.Client.Close()
-		sftpClient.Close()
-		return err
+		return fmt.Errorf("failed to open SFTP session: %w", err)
 	}
 
-	s.Client = sftpClient
-	s.ssh = sshSvc
+	s.client = sftpClient
+	s.sshClient = sshClient
 
 	return nil
 }
 
+// Closes the SFTP session, leaving the shared SSH connection open.
 func (s *Service) Disconnect() error {
-	if s.ssh != nil {
-		if err := s.ssh.Disconnect(); err != nil {
-			return err
-		}
-	}
-	if s.Client != nil {
-		return s.Client.Close()
-	}
-	return nil
-}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func (s *Service) SendCommands(cmds ...string) ([]byte, error) {
-	return s.ssh.SendCommands(cmds...)
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	s.sshClient = nil
+	return err
 }
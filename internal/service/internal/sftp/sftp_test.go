@@ -0,0 +1,42 @@
+package sftp_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+func Test_Client_Reopens_Session_Once_Connection_Is_Lost(t *testing.T) {
+	server := servicetest.NewServer(t)
+	server.WriteFile(t, "/volume1/movies/Movie (2024)/Movie (2024).mkv", "content")
+	previous, err := svc.SFTP.Client()
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SSH.Disconnect())
+
+	client, err := svc.SFTP.Client()
+	require.NoError(t, err)
+	info, err := client.Stat("/volume1/movies/Movie (2024)/Movie (2024).mkv")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("content")), info.Size())
+	assert.NotSame(t, previous, client)
+}
+
+func Test_Client_Fails_When_Connection_Cannot_Be_Dialed_Again(t *testing.T) {
+	servicetest.NewServer(t)
+	_, err := svc.SFTP.Client()
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SSH.Disconnect())
+	configtest.Set(t, map[string]any{config.KeySSHHost: "127.0.0.1", config.KeySSHPort: "1"})
+
+	client, err := svc.SFTP.Client()
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
@@ -0,0 +1,151 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+)
+
+const (
+	// Environment variable holding the passphrase of encrypted private keys, for non-interactive use.
+	PassphraseEnv = "NAS_CLI_SSH_PASSPHRASE"
+)
+
+// Returns the public key authentication method offering keys held by ssh-agent, if enabled and available, followed by
+// given private key files. Signers are loaded once and shared by every hop of the connection.
+func authMethod(useAgent bool, keyPaths []string, p prompt.Prompter) (method ssh.AuthMethod, closeFunc func()) {
+	closeFunc = func() {}
+
+	var agentClient agent.ExtendedAgent
+	if socket := os.Getenv("SSH_AUTH_SOCK"); useAgent && socket != "" {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			agentClient = agent.NewClient(conn)
+			closeFunc = func() { conn.Close() }
+		}
+	}
+
+	// All keys must be offered by a single method: the SSH client does not try the same method twice.
+	loadSigners := sync.OnceValues(func() ([]ssh.Signer, error) {
+		var signers []ssh.Signer
+		if agentClient != nil {
+			agentSigners, err := agentClient.Signers()
+			if err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+
+		fileSigners, err := loadFileSigners(keyPaths, p)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, fileSigners...)
+
+		if len(signers) == 0 {
+			return nil, fmt.Errorf("no private key found and no ssh-agent available")
+		}
+		return signers, nil
+	})
+
+	return ssh.PublicKeysCallback(loadSigners), closeFunc
+}
+
+// Loads signers from given private key files, skipping the ones that do not exist.
+func loadFileSigners(keyPaths []string, p prompt.Prompter) ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+	for _, keyPath := range keyPaths {
+		keyBytes, err := os.ReadFile(keyPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to read private key: %w", err)
+		}
+
+		signer, err := parsePrivateKey(keyPath, keyBytes, p)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	return signers, nil
+}
+
+// Parses given private key. Encrypted keys exposing their public key are only decrypted once the server accepted it,
+// so that passphrases are not asked for needlessly. Passphrases are read from the environment, or asked with given
+// prompter.
+func parsePrivateKey(keyPath string, keyBytes []byte, p prompt.Prompter) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err == nil {
+		return signer, nil
+	}
+
+	var missingErr *ssh.PassphraseMissingError
+	if !errors.As(err, &missingErr) {
+		return nil, fmt.Errorf("unable to parse private key %s: %w", keyPath, err)
+	}
+
+	decrypt := func() (ssh.Signer, error) {
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			passphrase, err = p.Password(fmt.Sprintf("Passphrase for %s", keyPath))
+			if err != nil {
+				return nil, fmt.Errorf("unable to get passphrase for private key %s: %w", keyPath, err)
+			}
+		}
+
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt private key %s: %w", keyPath, err)
+		}
+		return signer, nil
+	}
+
+	if missingErr.PublicKey == nil {
+		return decrypt()
+	}
+
+	return &lazySigner{
+		publicKey: missingErr.PublicKey,
+		load:      sync.OnceValues(decrypt),
+	}, nil
+}
+
+// Signer of an encrypted private key, decrypted on first signature.
+type lazySigner struct {
+	publicKey ssh.PublicKey
+	load      func() (ssh.Signer, error)
+}
+
+func (s *lazySigner) PublicKey() ssh.PublicKey {
+	return s.publicKey
+}
+
+func (s *lazySigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	signer, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(rand, data)
+}
+
+func (s *lazySigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	signer, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+	if !ok {
+		return nil, fmt.Errorf("private key does not support %s signatures", algorithm)
+	}
+	return algorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+}
@@ -0,0 +1,83 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/jeremiergz/nas-cli/internal/prompt"
+)
+
+// Prompter answering passphrase prompts with given passphrase.
+type passphrasePrompter struct {
+	prompt.AutoPrompter
+	passphrase string
+	prompts    int
+}
+
+func (p *passphrasePrompter) Password(_ string) (string, error) {
+	p.prompts++
+	return p.passphrase, nil
+}
+
+func Test_ParsePrivateKey_Decrypts_Lazily(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte("secret"))
+	require.NoError(t, err)
+
+	p := &passphrasePrompter{passphrase: "secret"}
+
+	signer, err := parsePrivateKey("id_test", pem.EncodeToMemory(block), p)
+	require.NoError(t, err)
+
+	expected, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, expected.PublicKey().Marshal(), signer.PublicKey().Marshal())
+	assert.Equal(t, 0, p.prompts)
+
+	signature, err := signer.Sign(rand.Reader, []byte("data"))
+	require.NoError(t, err)
+	assert.NoError(t, signer.PublicKey().Verify([]byte("data"), signature))
+
+	_, err = signer.Sign(rand.Reader, []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.prompts)
+}
+
+func Test_ParsePrivateKey_Uses_Passphrase_From_Environment(t *testing.T) {
+	t.Setenv(PassphraseEnv, "wrong")
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte("secret"))
+	require.NoError(t, err)
+
+	signer, err := parsePrivateKey("id_test", pem.EncodeToMemory(block), prompt.NewAuto())
+	require.NoError(t, err)
+
+	_, err = signer.Sign(rand.Reader, []byte("data"))
+	assert.ErrorContains(t, err, "unable to decrypt private key id_test")
+}
+
+func Test_ParsePrivateKey_Fails_Without_Prompting(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte("secret"))
+	require.NoError(t, err)
+
+	signer, err := parsePrivateKey("id_test", pem.EncodeToMemory(block), prompt.NewAuto())
+	require.NoError(t, err)
+
+	_, err = signer.Sign(rand.Reader, []byte("data"))
+	assert.ErrorContains(t, err, "unable to get passphrase for private key id_test")
+}
@@ -0,0 +1,73 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Result of a command run on the server.
+type CommandOutput struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// Runs given command on the server without a PTY, capturing its output streams. A non-zero exit status is not an
+// error: it is reported through ExitCode.
+func (s *Service) Run(cmd string) (*CommandOutput, error) {
+	client, err := s.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session on server: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	output := &CommandOutput{}
+	err = session.Run(cmd)
+	output.Stdout = stdout.Bytes()
+	output.Stderr = stderr.Bytes()
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		output.ExitCode = exitErr.ExitStatus()
+		return output, nil
+	}
+	if err != nil {
+		return output, fmt.Errorf("failed to execute command '%s' on server: %w", cmd, err)
+	}
+
+	return output, nil
+}
+
+// Runs given commands in a single shell on the server and returns their standard output. Fails if the last command
+// exits with a non-zero status, including its error output.
+func (s *Service) SendCommands(cmds ...string) ([]byte, error) {
+	cmd := strings.Join(cmds, "; ")
+
+	output, err := s.Run(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if output.ExitCode != 0 {
+		return output.Stdout, fmt.Errorf(
+			"command '%s' exited with status %d on server: %s",
+			cmd,
+			output.ExitCode,
+			strings.TrimSpace(string(output.Stderr)),
+		)
+	}
+
+	return output.Stdout, nil
+}
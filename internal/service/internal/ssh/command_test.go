@@ -0,0 +1,80 @@
+package ssh_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/service/servicetest"
+)
+
+// Starts a server on which "fail" writes to stderr and exits with status 2, and any other command is echoed.
+func setupCommandServer(t *testing.T) *servicetest.Server {
+	t.Helper()
+
+	server := servicetest.NewServer(t)
+	server.HandleCommand("", func(cmd string, stdout, _ io.Writer) int {
+		fmt.Fprintln(stdout, cmd)
+		return 0
+	})
+	server.HandleCommand("fail", func(_ string, _, stderr io.Writer) int {
+		fmt.Fprint(stderr, "failure\n")
+		return 2
+	})
+	return server
+}
+
+func Test_Run_Captures_Output_Without_PTY(t *testing.T) {
+	server := setupCommandServer(t)
+
+	output, err := svc.SSH.Run("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(output.Stdout))
+	assert.Empty(t, output.Stderr)
+	assert.Equal(t, 0, output.ExitCode)
+
+	output, err = svc.SSH.Run("fail")
+	require.NoError(t, err)
+	assert.Empty(t, output.Stdout)
+	assert.Equal(t, "failure\n", string(output.Stderr))
+	assert.Equal(t, 2, output.ExitCode)
+
+	assert.NotContains(t, server.RequestTypes(), "pty-req")
+	assert.Equal(t, 1, server.Connections())
+}
+
+func Test_SendCommands_Returns_Error_Output(t *testing.T) {
+	setupCommandServer(t)
+
+	stdout, err := svc.SSH.SendCommands("first", "second")
+	require.NoError(t, err)
+	assert.Equal(t, "first; second\n", string(stdout))
+
+	_, err = svc.SSH.SendCommands("fail")
+	assert.EqualError(t, err, "command 'fail' exited with status 2 on server: failure")
+}
+
+func Test_Client_Reconnects_After_Connection_Loss(t *testing.T) {
+	server := setupCommandServer(t)
+
+	first, err := svc.SSH.Client()
+	require.NoError(t, err)
+
+	server.DropConnections()
+	first.Wait()
+	require.Eventually(t, func() bool {
+		client, err := svc.SSH.Client()
+		return err == nil && client != first
+	}, time.Second, 10*time.Millisecond)
+
+	output, err := svc.SSH.Run("again")
+	require.NoError(t, err)
+	assert.Equal(t, "again\n", string(output.Stdout))
+
+	assert.Equal(t, 2, server.Connections())
+}
@@ -0,0 +1,78 @@
+package ssh
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+)
+
+// Address and user of a host to connect to, once resolved through ssh_config.
+type endpoint struct {
+	Host          string
+	Port          string
+	User          string
+	IdentityFiles []string
+}
+
+func (e *endpoint) Addr() string {
+	return net.JoinHostPort(e.Host, e.Port)
+}
+
+// Resolves given host alias through ssh_config. As with OpenSSH, given port and user take precedence over the Port
+// and User entries of the alias, which are only used when they are empty. The port falls back to the default SSH port
+// while the user is left empty when found nowhere.
+func resolveEndpoint(cfg *sshConfig, alias, port, user string) *endpoint {
+	e := &endpoint{
+		Host: alias,
+		Port: cmp.Or(port, cfg.Get(alias, "Port"), config.DefaultSSHPort),
+		User: cmp.Or(user, cfg.Get(alias, "User")),
+	}
+
+	if hostName := cfg.Get(alias, "HostName"); hostName != "" {
+		e.Host = strings.ReplaceAll(hostName, "%h", alias)
+	}
+	for _, identityFile := range cfg.GetAll(alias, "IdentityFile") {
+		e.IdentityFiles = append(e.IdentityFiles, expandHome(identityFile))
+	}
+
+	return e
+}
+
+// Parses a comma-separated list of jump hosts as used by ProxyJump, e.g. "admin@bastion:2222,gateway". Each host may
+// itself be an ssh_config alias. Returns no jump host for an empty spec or "none".
+func parseJumpHosts(cfg *sshConfig, spec, defaultUser string) ([]*endpoint, error) {
+	if spec == "" || strings.EqualFold(spec, "none") {
+		return nil, nil
+	}
+
+	var jumps []*endpoint
+	for _, hostSpec := range strings.Split(spec, ",") {
+		hostSpec = strings.TrimPrefix(strings.TrimSpace(hostSpec), "ssh://")
+
+		user, hostPort, hasUser := strings.Cut(hostSpec, "@")
+		if !hasUser {
+			user, hostPort = "", hostSpec
+		}
+
+		host, port := hostPort, ""
+		if strings.Contains(hostPort, ":") {
+			var err error
+			host, port, err = net.SplitHostPort(hostPort)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jump host %q: %w", hostSpec, err)
+			}
+		}
+		if host == "" {
+			return nil, fmt.Errorf("invalid jump host %q", hostSpec)
+		}
+
+		jump := resolveEndpoint(cfg, host, port, user)
+		jump.User = cmp.Or(jump.User, defaultUser)
+		jumps = append(jumps, jump)
+	}
+
+	return jumps, nil
+}
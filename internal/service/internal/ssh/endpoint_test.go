@@ -0,0 +1,114 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+)
+
+const testSSHConfig = `
+Host nas
+	HostName 192.168.1.10
+	Port 2222
+	IdentityFile ~/.ssh/id_nas
+	ProxyJump bastion
+
+Host bastion
+	HostName=bastion.example.com
+	User admin
+
+Host *.example.com !bastion.example.com
+	Port 2200
+
+Match host nas
+	User ignored
+
+Host *
+	User fallback
+`
+
+func Test_ResolveEndpoint_With_SSH_Config(t *testing.T) {
+	cfg, err := parseSSHConfig(strings.NewReader(testSSHConfig))
+	require.NoError(t, err)
+
+	nas := resolveEndpoint(cfg, "nas", "", "")
+	assert.Equal(t, "192.168.1.10:2222", nas.Addr())
+	assert.Equal(t, "fallback", nas.User)
+	assert.Len(t, nas.IdentityFiles, 1)
+	assert.True(t, strings.HasSuffix(nas.IdentityFiles[0], "/.ssh/id_nas"))
+	assert.Equal(t, "bastion", cfg.Get("nas", "proxyjump"))
+
+	other := resolveEndpoint(cfg, "other.example.com", "", "")
+	assert.Equal(t, "other.example.com:2200", other.Addr())
+
+	unknown := resolveEndpoint(&sshConfig{}, "nas.local", "", "me")
+	assert.Equal(t, "nas.local:22", unknown.Addr())
+	assert.Equal(t, "me", unknown.User)
+}
+
+func Test_ResolveEndpoint_Prefers_Explicit_Values(t *testing.T) {
+	cfg, err := parseSSHConfig(strings.NewReader(testSSHConfig))
+	require.NoError(t, err)
+
+	nas := resolveEndpoint(cfg, "nas", "2022", "me")
+
+	assert.Equal(t, "192.168.1.10:2022", nas.Addr())
+	assert.Equal(t, "me", nas.User)
+}
+
+func Test_ParseJumpHosts(t *testing.T) {
+	cfg, err := parseSSHConfig(strings.NewReader(testSSHConfig))
+	require.NoError(t, err)
+
+	jumps, err := parseJumpHosts(cfg, "bastion, root@gateway:2022", "me")
+	require.NoError(t, err)
+	require.Len(t, jumps, 2)
+	assert.Equal(t, "bastion.example.com:22", jumps[0].Addr())
+	assert.Equal(t, "admin", jumps[0].User)
+	assert.Equal(t, "gateway:2022", jumps[1].Addr())
+	assert.Equal(t, "root", jumps[1].User)
+
+	jumps, err = parseJumpHosts(cfg, "none", "me")
+	require.NoError(t, err)
+	assert.Empty(t, jumps)
+
+	_, err = parseJumpHosts(cfg, "root@:22", "me")
+	assert.Error(t, err)
+}
+
+func Test_LoadSettings_Configured_Defaults_Win_Over_SSH_Config(t *testing.T) {
+	sshConfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(sshConfigPath, []byte("Host nas\n\tPort 2222\n\tUser admin\n"), 0o600))
+	configtest.Set(t, map[string]any{
+		config.KeySSHClientConfig:     sshConfigPath,
+		config.KeySSHClientKnownHosts: filepath.Join(t.TempDir(), "known_hosts"),
+		config.KeySSHHost:             "nas",
+		config.KeySSHJump:             "",
+		config.KeySSHPort:             config.DefaultSSHPort,
+		config.KeySSHUser:             config.DefaultSSHUser(),
+	})
+
+	cfg, err := loadSettings()
+	require.NoError(t, err)
+	assert.Equal(t, "nas:2222", cfg.target.Addr())
+	assert.Equal(t, "admin", cfg.target.User)
+
+	viper.SetConfigType("yaml")
+	content := fmt.Sprintf("ssh:\n  port: %q\n  user: %q\n", config.DefaultSSHPort, config.DefaultSSHUser())
+	require.NoError(t, viper.ReadConfig(strings.NewReader(content)))
+	t.Cleanup(func() { viper.ReadConfig(strings.NewReader("")) })
+
+	cfg, err = loadSettings()
+	require.NoError(t, err)
+	assert.Equal(t, "nas:22", cfg.target.Addr())
+	assert.Equal(t, config.DefaultSSHUser(), cfg.target.User)
+}
@@ -0,0 +1,193 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type HostKeyStatus string
+
+const (
+	// Host key is already present in the known hosts file.
+	HostKeyKnown HostKeyStatus = "known"
+
+	// No host key of the same type is present in the known hosts file.
+	HostKeyUnknown HostKeyStatus = "unknown"
+
+	// Known hosts file holds a different host key of the same type, which may be a man-in-the-middle attack.
+	HostKeyChanged HostKeyStatus = "changed"
+)
+
+var (
+	errHostKeyCaptured = errors.New("host key captured")
+)
+
+// Host key presented by a server, along with how it relates to the known hosts file.
+type HostKey struct {
+	Address string
+	Key     ssh.PublicKey
+	Status  HostKeyStatus
+
+	// Known keys of the same type, set when Status is HostKeyChanged.
+	KnownKeys []knownhosts.KnownKey
+
+	knownHosts string
+}
+
+// Returns the SHA256 fingerprint of the host key, as printed by OpenSSH.
+func (hk *HostKey) Fingerprint() string {
+	return ssh.FingerprintSHA256(hk.Key)
+}
+
+// Appends the host key to the known hosts file, creating it if needed.
+func (hk *HostKey) Trust() error {
+	if err := os.MkdirAll(filepath.Dir(hk.knownHosts), 0o700); err != nil {
+		return fmt.Errorf("unable to create known hosts directory: %w", err)
+	}
+
+	file, err := os.OpenFile(hk.knownHosts, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to open known hosts file %s: %w", hk.knownHosts, err)
+	}
+	defer file.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hk.Address)}, hk.Key)
+	if _, err := fmt.Fprintln(file, line); err != nil {
+		return fmt.Errorf("unable to write known hosts file %s: %w", hk.knownHosts, err)
+	}
+
+	return nil
+}
+
+// Retrieves the host key of every hop up to the configured host, jump hosts first. Given function is called for each of
+// them before going further, so that unknown keys can be trusted: reaching a host through a jump host requires the
+// jump host to be trusted and authenticated.
+func (s *Service) ScanHostKeys(visit func(hk *HostKey) error) error {
+	cfg, err := loadSettings()
+	if err != nil {
+		return err
+	}
+
+	auth, closeAgent := authMethod(cfg.useAgent, cfg.keyPaths, s.prompter)
+	defer closeAgent()
+
+	hops := slices.Concat(cfg.jumps, []*endpoint{cfg.target})
+	for index, hop := range hops {
+		hostKeyCallback, err := knownHostsCallback(cfg.knownHosts)
+		if err != nil {
+			return err
+		}
+
+		hk, err := scanHostKey(hop, cfg.knownHosts, hostKeyCallback, func() (net.Conn, func(), error) {
+			if index == 0 {
+				conn, err := net.Dial("tcp", hop.Addr())
+				return conn, func() {}, err
+			}
+
+			client, jumpClients, err := dial(hops[index-1], hops[:index-1], cfg.clientConfig(auth, hostKeyCallback))
+			if err != nil {
+				return nil, nil, err
+			}
+			closeFunc := func() {
+				client.Close()
+				closeClients(jumpClients)
+			}
+			conn, err := client.Dial("tcp", hop.Addr())
+			if err != nil {
+				closeFunc()
+				return nil, nil, fmt.Errorf("unable to reach %s through jump host: %w", hop.Addr(), err)
+			}
+			return conn, closeFunc, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := visit(hk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns a host key callback checking keys against given known hosts file, which is empty if it does not exist yet.
+func knownHostsCallback(knownHosts string) (ssh.HostKeyCallback, error) {
+	if _, err := os.Stat(knownHosts); os.IsNotExist(err) {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			return &knownhosts.KeyError{}
+		}, nil
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read known hosts file %s: %w", knownHosts, err)
+	}
+	return hostKeyCallback, nil
+}
+
+// Starts an SSH handshake with given host to retrieve its host key, then aborts it before authenticating.
+func scanHostKey(
+	hop *endpoint,
+	knownHosts string,
+	hostKeyCallback ssh.HostKeyCallback,
+	connect func() (net.Conn, func(), error),
+) (*HostKey, error) {
+	conn, closeFunc, err := connect()
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", hop.Addr(), err)
+	}
+	defer closeFunc()
+	defer conn.Close()
+
+	hk := &HostKey{
+		Address:    hop.Addr(),
+		knownHosts: knownHosts,
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User: hop.User,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hk.Key = key
+			hk.Status, hk.KnownKeys = hostKeyStatus(hostKeyCallback(hostname, remote, key), key)
+			return errHostKeyCaptured
+		},
+	}
+
+	_, _, _, err = ssh.NewClientConn(conn, hop.Addr(), clientConfig)
+	if hk.Key == nil {
+		return nil, fmt.Errorf("unable to retrieve host key of %s: %w", hop.Addr(), err)
+	}
+
+	return hk, nil
+}
+
+// Interprets the result of a known hosts check, returning known keys of the same type if the key changed. A mismatching
+// key only counts as changed when a known key has the same type, servers offering several key types being common.
+func hostKeyStatus(err error, key ssh.PublicKey) (HostKeyStatus, []knownhosts.KnownKey) {
+	if err == nil {
+		return HostKeyKnown, nil
+	}
+
+	var knownKeys []knownhosts.KnownKey
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		for _, known := range keyErr.Want {
+			if known.Key.Type() == key.Type() {
+				knownKeys = append(knownKeys, known)
+			}
+		}
+	}
+	if len(knownKeys) > 0 {
+		return HostKeyChanged, knownKeys
+	}
+
+	return HostKeyUnknown, nil
+}
@@ -1,27 +1,159 @@
 package ssh
 
 import (
+	"cmp"
 	"fmt"
-	"log"
-	"os"
-	"strings"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/knownhosts"
 
 	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/prompt"
 )
 
+var (
+	// Delay between two keepalive requests sent to the server.
+	KeepAliveInterval = 30 * time.Second
+)
+
+// Shared SSH connection to the configured host, dialed on first use and dialed again once lost.
 type Service struct {
-	Client *ssh.Client
+	mu     sync.Mutex
+	client *ssh.Client
+
+	// Connections to jump hosts the client goes through, closed along with it.
+	jumpClients []*ssh.Client
+	closeAgent  func()
+
+	// Asks for passphrases of encrypted private keys not set in the environment.
+	prompter prompt.Prompter
 }
 
 func New() *Service {
-	return &Service{}
+	return &Service{prompter: prompt.NewInteractive()}
 }
 
+// Sets the prompter asking for passphrases of encrypted private keys, e.g. one that never prompts when running
+// non-interactively.
+func (s *Service) SetPrompter(p prompt.Prompter) *Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prompter = p
+	return s
+}
+
+// Connects to the configured host unless already connected. Host aliases are resolved through the configured
+// ssh_config file, and the connection goes through the configured jump host or the ProxyJump of the alias if any. Keys
+// held by ssh-agent are offered before the configured private key and IdentityFile entries.
 func (s *Service) Connect() error {
+	_, err := s.Client()
+	return err
+}
+
+// Returns the shared client, connecting first if never connected or if the connection was lost.
+func (s *Service) Client() (*ssh.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	cfg, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := knownhosts.New(cfg.knownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read known hosts file %s, run config ssh trust to populate it: %w", cfg.knownHosts, err)
+	}
+
+	auth, closeAgent := authMethod(cfg.useAgent, cfg.keyPaths, s.prompter)
+
+	client, jumpClients, err := dial(cfg.target, cfg.jumps, cfg.clientConfig(auth, hostKeyCallback))
+	if err != nil {
+		closeAgent()
+		return nil, err
+	}
+
+	s.client = client
+	s.jumpClients = jumpClients
+	s.closeAgent = closeAgent
+
+	go s.watch(client)
+
+	return client, nil
+}
+
+// Sends keepalive requests to the server until the connection is closed, then forgets it so that next use dials again.
+func (s *Service) watch(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(KeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+					client.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	client.Wait()
+	close(done)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == client {
+		s.reset()
+	}
+}
+
+// Closes the shared connection. Next use dials again.
+func (s *Service) Disconnect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if s.client != nil {
+		err = s.client.Close()
+	}
+	s.reset()
+	return err
+}
+
+func (s *Service) reset() {
+	closeClients(s.jumpClients)
+	if s.closeAgent != nil {
+		s.closeAgent()
+	}
+	s.client = nil
+	s.jumpClients = nil
+	s.closeAgent = nil
+}
+
+// Connection settings resolved from the configuration and ssh_config.
+type settings struct {
+	target     *endpoint
+	jumps      []*endpoint
+	knownHosts string
+	keyPaths   []string
+	useAgent   bool
+}
+
+func loadSettings() (*settings, error) {
 	sshHost := viper.GetString(config.KeySSHHost)
 	sshKnownHosts := viper.GetString(config.KeySSHClientKnownHosts)
 	sshPort := viper.GetString(config.KeySSHPort)
@@ -31,72 +163,95 @@ func (s *Service) Connect() error {
 		config.KeySSHHost:             sshHost,
 		config.KeySSHClientKnownHosts: sshKnownHosts,
 		config.KeySSHPort:             sshPort,
-		config.KeySSHClientPrivateKey: sshPrivateKey,
 		config.KeySSHUser:             username,
 	}
 	for key, value := range requiredConfig {
 		if value == "" {
-			return fmt.Errorf("required variable %s is not defined", key)
+			return nil, fmt.Errorf("required variable %s is not defined", key)
 		}
 	}
 
-	keyBytes, err := os.ReadFile(sshPrivateKey)
+	sshCfg, err := readSSHConfig(viper.GetString(config.KeySSHClientConfig))
 	if err != nil {
-		return fmt.Errorf("unable to read private key: %v", err)
-	}
-	signer, err := ssh.ParsePrivateKey(keyBytes)
-	if err != nil {
-		return fmt.Errorf("unable to parse private key: %v", err)
-	}
-	hostKeyCallback, _ := knownhosts.New(sshKnownHosts)
-	sshConfig := &ssh.ClientConfig{
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: hostKeyCallback,
-		User:            username,
+		return nil, err
 	}
 
-	sshClient, err := ssh.Dial("tcp", fmt.Sprintf("%s:%s", sshHost, sshPort), sshConfig)
+	// Values left to their default do not override ssh_config, as OpenSSH defaults do not either. Values configured on
+	// purpose do, even when equal to the default.
+	explicitPort := lo.Ternary(config.IsConfigured(config.KeySSHPort) || sshPort != config.DefaultSSHPort, sshPort, "")
+	explicitUser := lo.Ternary(config.IsConfigured(config.KeySSHUser) || username != config.DefaultSSHUser(), username, "")
+	target := resolveEndpoint(sshCfg, sshHost, explicitPort, explicitUser)
+	target.User = cmp.Or(target.User, username)
+
+	jumpSpec := viper.GetString(config.KeySSHJump)
+	if jumpSpec == "" {
+		jumpSpec = sshCfg.Get(sshHost, "ProxyJump")
+	}
+	jumps, err := parseJumpHosts(sshCfg, jumpSpec, username)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	s.Client = sshClient
+	keyPaths := []string{}
+	if sshPrivateKey != "" {
+		keyPaths = append(keyPaths, sshPrivateKey)
+	}
+	for _, e := range slices.Concat(jumps, []*endpoint{target}) {
+		keyPaths = append(keyPaths, e.IdentityFiles...)
+	}
 
-	return nil
+	return &settings{
+		target:     target,
+		jumps:      jumps,
+		knownHosts: sshKnownHosts,
+		keyPaths:   lo.Uniq(keyPaths),
+		useAgent:   viper.GetBool(config.KeySSHClientAgent),
+	}, nil
 }
 
-func (s *Service) Disconnect() error {
-	if s.Client.Close() != nil {
-		return s.Client.Close()
+func (cfg *settings) clientConfig(auth ssh.AuthMethod, hostKeyCallback ssh.HostKeyCallback) func(e *endpoint) *ssh.ClientConfig {
+	return func(e *endpoint) *ssh.ClientConfig {
+		return &ssh.ClientConfig{
+			Auth:            []ssh.AuthMethod{auth},
+			HostKeyCallback: hostKeyCallback,
+			User:            e.User,
+		}
 	}
-	return nil
 }
 
-func (s *Service) SendCommands(cmds ...string) ([]byte, error) {
-	session, err := s.Client.NewSession()
+// Dials given target, going through given jump hosts in order. Returns the target client along with jump host clients.
+func dial(target *endpoint, jumps []*endpoint, clientConfig func(e *endpoint) *ssh.ClientConfig) (*ssh.Client, []*ssh.Client, error) {
+	hops := slices.Concat(jumps, []*endpoint{target})
+
+	client, err := ssh.Dial("tcp", hops[0].Addr(), clientConfig(hops[0]))
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, fmt.Errorf("unable to connect to %s: %w", hops[0].Addr(), err)
 	}
-	defer session.Close()
 
-	modes := ssh.TerminalModes{
-		ssh.ECHO:          0,
-		ssh.TTY_OP_ISPEED: 14400,
-		ssh.TTY_OP_OSPEED: 14400,
-	}
+	var jumpClients []*ssh.Client
+	for _, hop := range hops[1:] {
+		jumpClients = append(jumpClients, client)
 
-	err = session.RequestPty("xterm", 80, 40, modes)
-	if err != nil {
-		return []byte{}, err
-	}
+		conn, err := client.Dial("tcp", hop.Addr())
+		if err != nil {
+			closeClients(jumpClients)
+			return nil, nil, fmt.Errorf("unable to reach %s through jump host: %w", hop.Addr(), err)
+		}
 
-	cmd := strings.Join(cmds, "; ")
-	output, err := session.Output(cmd)
-	if err != nil {
-		return output, fmt.Errorf("failed to execute command '%s' on server: %v", cmd, err)
+		clientConn, chans, reqs, err := ssh.NewClientConn(conn, hop.Addr(), clientConfig(hop))
+		if err != nil {
+			conn.Close()
+			closeClients(jumpClients)
+			return nil, nil, fmt.Errorf("unable to connect to %s: %w", hop.Addr(), err)
+		}
+		client = ssh.NewClient(clientConn, chans, reqs)
 	}
 
-	return output, err
+	return client, jumpClients, nil
+}
+
+func closeClients(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		clients[i].Close()
+	}
 }
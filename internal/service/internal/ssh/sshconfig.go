@@ -0,0 +1,147 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Subset of an OpenSSH client configuration file, as documented in ssh_config(5). Only Host blocks are supported,
+// Match and Include directives are ignored.
+type sshConfig struct {
+	blocks []*sshConfigBlock
+}
+
+type sshConfigBlock struct {
+	patterns []string
+	options  [][2]string
+}
+
+// Reads the OpenSSH client configuration file at given path. Returns an empty configuration if it does not exist.
+func readSSHConfig(filePath string) (*sshConfig, error) {
+	if filePath == "" {
+		return &sshConfig{}, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &sshConfig{}, nil
+		}
+		return nil, fmt.Errorf("unable to read ssh config %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	cfg, err := parseSSHConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse ssh config %s: %w", filePath, err)
+	}
+
+	return cfg, nil
+}
+
+func parseSSHConfig(r io.Reader) (*sshConfig, error) {
+	// Options set before any Host line apply to every host.
+	current := &sshConfigBlock{patterns: []string{"*"}}
+	cfg := &sshConfig{blocks: []*sshConfigBlock{current}}
+	skipping := false
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		separatorIndex := strings.IndexAny(line, " \t=")
+		if separatorIndex < 0 {
+			return nil, fmt.Errorf("invalid line %d: %q", lineNumber, line)
+		}
+		key := strings.ToLower(line[:separatorIndex])
+		value := strings.TrimSpace(line[separatorIndex+1:])
+		value = strings.Trim(strings.TrimSpace(strings.TrimPrefix(value, "=")), `"`)
+
+		switch key {
+		case "host":
+			current = &sshConfigBlock{patterns: strings.Fields(value)}
+			cfg.blocks = append(cfg.blocks, current)
+			skipping = false
+
+		case "match":
+			skipping = true
+
+		case "include":
+			continue
+
+		default:
+			if !skipping {
+				current.options = append(current.options, [2]string{key, value})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Returns the first value of given option for given host alias, following OpenSSH precedence rules.
+func (c *sshConfig) Get(alias, key string) string {
+	values := c.GetAll(alias, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Returns all values of given option for given host alias, in file order.
+func (c *sshConfig) GetAll(alias, key string) []string {
+	key = strings.ToLower(key)
+
+	var values []string
+	for _, block := range c.blocks {
+		if !block.matches(alias) {
+			continue
+		}
+		for _, option := range block.options {
+			if option[0] == key {
+				values = append(values, option[1])
+			}
+		}
+	}
+	return values
+}
+
+func (b *sshConfigBlock) matches(alias string) bool {
+	matched := false
+	for _, pattern := range b.patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		ok, _ := path.Match(strings.TrimPrefix(pattern, "!"), alias)
+		if ok && negated {
+			return false
+		}
+		if ok {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// Expands "~" and "%d" home directory references used in ssh_config paths.
+func expandHome(filePath string) string {
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return filePath
+	}
+	if filePath == "~" || strings.HasPrefix(filePath, "~/") {
+		filePath = filepath.Join(homedir, strings.TrimPrefix(filePath, "~"))
+	}
+	return strings.ReplaceAll(filePath, "%d", homedir)
+}
@@ -0,0 +1,23 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Uploads given JPEG image as the poster of given item and selects it.
+func (p *Service) UploadPoster(ctx context.Context, ratingKey string, content io.Reader) error {
+	if err := p.Upload(ctx, fmt.Sprintf("/library/metadata/%s/posters", ratingKey), "image/jpeg", content); err != nil {
+		return fmt.Errorf("failed to upload poster: %w", err)
+	}
+	return nil
+}
+
+// Uploads given JPEG image as the background art of given item and selects it.
+func (p *Service) UploadArt(ctx context.Context, ratingKey string, content io.Reader) error {
+	if err := p.Upload(ctx, fmt.Sprintf("/library/metadata/%s/arts", ratingKey), "image/jpeg", content); err != nil {
+		return fmt.Errorf("failed to upload background: %w", err)
+	}
+	return nil
+}
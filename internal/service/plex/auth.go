@@ -0,0 +1,69 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// Base URL of the Plex account service, used to obtain authentication tokens.
+	DefaultAuthURL = "https://plex.tv"
+)
+
+// PIN used to link the application to a Plex account.
+type PIN struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	AuthToken string    `json:"authToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Requests a new PIN that the user must claim by entering its code on the Plex link page.
+//
+// The service must target the Plex account service and be created with WithClientIdentifier.
+func (p *Service) RequestPIN(ctx context.Context) (*PIN, error) {
+	var pin PIN
+	if err := p.Post(ctx, "/api/v2/pins", url.Values{"strong": {"false"}}, &pin); err != nil {
+		return nil, fmt.Errorf("failed to request PIN: %w", err)
+	}
+	if pin.ID == 0 || pin.Code == "" {
+		return nil, fmt.Errorf("failed to request PIN: empty response")
+	}
+	return &pin, nil
+}
+
+// Returns the current state of given PIN, whose AuthToken is set once claimed.
+func (p *Service) CheckPIN(ctx context.Context, id int) (*PIN, error) {
+	var pin PIN
+	if err := p.Get(ctx, fmt.Sprintf("/api/v2/pins/%d", id), &pin); err != nil {
+		return nil, fmt.Errorf("failed to check PIN: %w", err)
+	}
+	return &pin, nil
+}
+
+// Polls given PIN at given interval until it is claimed, expires or the context is done, and returns its token.
+func (p *Service) WaitForToken(ctx context.Context, pin *PIN, interval time.Duration) (string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		current, err := p.CheckPIN(ctx, pin.ID)
+		if err != nil {
+			return "", err
+		}
+		if current.AuthToken != "" {
+			return current.AuthToken, nil
+		}
+		if !current.ExpiresAt.IsZero() && time.Now().After(current.ExpiresAt) {
+			return "", fmt.Errorf("PIN %s expired before being claimed", pin.Code)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type LibraryKind string
+
+const (
+	LibraryKindAnimes  LibraryKind = "animes"
+	LibraryKindMovies  LibraryKind = "movies"
+	LibraryKindTVShows LibraryKind = "tvshows"
+)
+
+func (k LibraryKind) String() string {
+	return string(k)
+}
+
+// Returns the Plex section type holding this kind of media, e.g. "movie" or "show".
+func (k LibraryKind) SectionType() string {
+	switch k {
+	case LibraryKindMovies:
+		return "movie"
+	default:
+		return "show"
+	}
+}
+
+type Library struct {
+	ID        int
+	Name      string
+	Type      string
+	Locations []string
+}
+
+type librarySections struct {
+	MediaContainer struct {
+		Directory []struct {
+			Key      string `json:"key"`
+			Title    string `json:"title"`
+			Type     string `json:"type"`
+			Location []struct {
+				Path string `json:"path"`
+			} `json:"Location"`
+		} `json:"Directory"`
+	} `json:"MediaContainer"`
+}
+
+// Returns the libraries of the Plex server. Results are cached for the lifetime of the service.
+func (p *Service) Libraries(ctx context.Context) ([]Library, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.libraries == nil {
+		var sections librarySections
+		if err := p.Get(ctx, "/library/sections", &sections); err != nil {
+			return nil, fmt.Errorf("failed to get library sections: %w", err)
+		}
+
+		libraries := make([]Library, 0, len(sections.MediaContainer.Directory))
+		for _, d := range sections.MediaContainer.Directory {
+			id, err := strconv.Atoi(d.Key)
+			if err != nil {
+				return nil, fmt.Errorf("could not convert library ID to integer: %w", err)
+			}
+
+			library := Library{
+				ID:   id,
+				Name: d.Title,
+				Type: d.Type,
+			}
+			for _, location := range d.Location {
+				library.Locations = append(library.Locations, location.Path)
+			}
+			libraries = append(libraries, library)
+		}
+		p.libraries = libraries
+	}
+
+	return p.libraries, nil
+}
+
+// Returns the ID of the library holding given kind of media.
+//
+// The section configured for the kind is used when defined, either by ID or title. Otherwise, the library whose
+// title matches the kind once lowercased and stripped of spaces is used, e.g. "TV Shows" for tvshows.
+func (p *Service) LibraryID(ctx context.Context, kind LibraryKind) (int, error) {
+	libraries, err := p.Libraries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list libraries: %w", err)
+	}
+
+	if section := p.sections[kind]; section != "" {
+		for _, lib := range libraries {
+			if strconv.Itoa(lib.ID) == section || strings.EqualFold(lib.Name, section) {
+				return lib.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("could not find Plex section %q configured for %s", section, kind)
+	}
+
+	if lib := FindLibraryByName(libraries, kind); lib != nil {
+		return lib.ID, nil
+	}
+
+	return 0, fmt.Errorf("could not find library kind %s, map it to a Plex section with \"config plex discover\"", kind)
+}
+
+// Returns the library whose title matches given kind once lowercased and stripped of spaces, if any.
+func FindLibraryByName(libraries []Library, kind LibraryKind) *Library {
+	for _, lib := range libraries {
+		if strings.ToLower(strings.ReplaceAll(lib.Name, " ", "")) == kind.String() {
+			return &lib
+		}
+	}
+	return nil
+}
+
+// Triggers a scan of given library. Only given paths are scanned when provided, the whole library otherwise.
+func (p *Service) Refresh(ctx context.Context, libraryID int, paths ...string) error {
+	refreshPath := fmt.Sprintf("/library/sections/%d/refresh", libraryID)
+
+	if len(paths) == 0 {
+		if err := p.Get(ctx, refreshPath, nil); err != nil {
+			return fmt.Errorf("failed to refresh library %d: %w", libraryID, err)
+		}
+		return nil
+	}
+
+	// Plex only accepts one path per refresh request.
+	for _, path := range paths {
+		if err := p.Do(ctx, http.MethodGet, refreshPath, url.Values{"path": {path}}, nil, nil); err != nil {
+			return fmt.Errorf("failed to refresh %s in library %d: %w", path, libraryID, err)
+		}
+	}
+
+	return nil
+}
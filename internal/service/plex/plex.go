@@ -1,47 +1,265 @@
 package plex
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
 )
 
 const (
+	// Name of the file used to store matching information for movies.
+	MovieMatchingFileName = ".plexmatch"
+
 	// Name of the file used to store matching information for TV shows.
 	ShowMatchingFileName = ".plexmatch"
+
+	// Maximum duration of a request when none is configured.
+	DefaultTimeout = 30 * time.Second
 )
 
+var (
+	// Returned when the Plex token is missing or invalid.
+	ErrUnauthorized = errors.New("unauthorized, check the Plex API token")
+
+	// Returned when the requested resource does not exist.
+	ErrNotFound = errors.New("resource not found")
+
+	// Returned when the Plex server fails to process the request.
+	ErrServer = errors.New("Plex server error")
+)
+
+// Error returned when Plex answers with a non-successful status code. Wraps ErrUnauthorized, ErrNotFound or ErrServer
+// depending on the status code.
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected HTTP status %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= 500:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
 type Service struct {
 	apiURL   string
 	apiToken string
+	client   *http.Client
+
+	clientID string
+	sections map[LibraryKind]string
+
+	mu        sync.Mutex
+	libraries []Library
+}
+
+// Customizes the Plex service.
+type Option func(*options)
+
+type options struct {
+	client   *http.Client
+	clientID string
+	insecure bool
+	sections map[LibraryKind]string
+	timeout  time.Duration
+}
+
+// Sets the maximum duration of each request. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
 }
 
-func NewService(apiURL, apiToken string) *Service {
+// Disables TLS certificate verification, which is common when Plex uses a self-signed certificate.
+func WithInsecureSkipVerify(insecure bool) Option {
+	return func(o *options) {
+		o.insecure = insecure
+	}
+}
+
+// Identifies this application to Plex, which is required by plex.tv endpoints.
+func WithClientIdentifier(clientID string) Option {
+	return func(o *options) {
+		o.clientID = clientID
+	}
+}
+
+// Maps library kinds to Plex section IDs or titles, used instead of matching section titles against the kind.
+func WithSections(sections map[LibraryKind]string) Option {
+	return func(o *options) {
+		o.sections = sections
+	}
+}
+
+// Uses given HTTP client as is, ignoring timeout and TLS options.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+func NewService(apiURL, apiToken string, opts ...Option) *Service {
+	o := &options{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	client := o.client
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if o.insecure {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		client = &http.Client{
+			Timeout:   o.timeout,
+			Transport: transport,
+		}
+	}
+
 	return &Service{
 		apiURL:   apiURL,
 		apiToken: apiToken,
+		client:   client,
+		clientID: o.clientID,
+		sections: o.sections,
+	}
+}
+
+// Returns a service configured with the Plex API configuration entries.
+func NewServiceFromConfig() *Service {
+	return NewService(
+		viper.GetString(config.KeyPlexAPIURL),
+		viper.GetString(config.KeyPlexAPIToken),
+		WithTimeout(configuredTimeout()),
+		WithInsecureSkipVerify(viper.GetBool(config.KeyPlexAPIInsecure)),
+		WithSections(map[LibraryKind]string{
+			LibraryKindAnimes:  viper.GetString(config.KeyPlexSectionsAnimes),
+			LibraryKindMovies:  viper.GetString(config.KeyPlexSectionsMovies),
+			LibraryKindTVShows: viper.GetString(config.KeyPlexSectionsTVShows),
+		}),
+	)
+}
+
+// Returns the configured request timeout. Bare numbers are read as seconds rather than nanoseconds, and values that are
+// not durations such as "30s" fall back to the default timeout.
+func configuredTimeout() time.Duration {
+	value := strings.TrimSpace(viper.GetString(config.KeyPlexAPITimeout))
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return DefaultTimeout
 	}
+	return timeout
 }
 
-func (p *Service) Get(path string, output any) error {
+// Performs a GET request and decodes the JSON response into output.
+func (p *Service) Get(ctx context.Context, path string, output any) error {
+	return p.Do(ctx, http.MethodGet, path, nil, nil, output)
+}
+
+// Performs a POST request with given query parameters and decodes the JSON response into output, if not nil.
+func (p *Service) Post(ctx context.Context, path string, query url.Values, output any) error {
+	return p.Do(ctx, http.MethodPost, path, query, nil, output)
+}
+
+// Performs a PUT request with given query parameters and decodes the JSON response into output, if not nil.
+func (p *Service) Put(ctx context.Context, path string, query url.Values, output any) error {
+	return p.Do(ctx, http.MethodPut, path, query, nil, output)
+}
+
+// Performs a DELETE request.
+func (p *Service) Delete(ctx context.Context, path string) error {
+	return p.Do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// Performs a request against the Plex API. The body, if not nil, is sent as JSON and the response is decoded into
+// output, if not nil.
+func (p *Service) Do(ctx context.Context, method, path string, query url.Values, body, output any) error {
+	if body == nil {
+		return p.do(ctx, method, path, query, "", nil, output)
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON request body: %w", err)
+	}
+
+	return p.do(ctx, method, path, query, "application/json", bytes.NewReader(bodyBytes), output)
+}
+
+// Sends given content as is with a POST request, e.g. to upload an image.
+func (p *Service) Upload(ctx context.Context, path, contentType string, content io.Reader) error {
+	return p.do(ctx, http.MethodPost, path, nil, contentType, content, nil)
+}
+
+func (p *Service) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	contentType string,
+	body io.Reader,
+	output any,
+) error {
 	targetURL, err := url.JoinPath(p.apiURL, path)
 	if err != nil {
 		return fmt.Errorf("failed to join URL path: %w", err)
 	}
+	if len(query) > 0 {
+		targetURL += "?" + query.Encode()
+	}
 
-	req, err := http.NewRequest("GET", targetURL, nil)
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, body)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	req.Header.Set("X-Plex-Token", p.apiToken)
+	if p.apiToken != "" {
+		req.Header.Set("X-Plex-Token", p.apiToken)
+	}
+	if p.clientID != "" {
+		req.Header.Set("X-Plex-Client-Identifier", p.clientID)
+		req.Header.Set("X-Plex-Product", config.AppName)
+	}
 	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to perform HTTP request: %w", err)
 	}
@@ -52,6 +270,19 @@ func (p *Service) Get(path string, output any) error {
 		return fmt.Errorf("failed to read HTTP response body: %w", err)
 	}
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Body:       summarizeBody(bodyBytes),
+		}
+	}
+
+	if output == nil || len(bytes.TrimSpace(bodyBytes)) == 0 {
+		return nil
+	}
+
 	err = json.Unmarshal(bodyBytes, output)
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal JSON response: %w", err)
@@ -60,71 +291,13 @@ func (p *Service) Get(path string, output any) error {
 	return nil
 }
 
-type LibraryKind string
-
-const (
-	LibraryKindAnimes  LibraryKind = "animes"
-	LibraryKindMovies  LibraryKind = "movies"
-	LibraryKindTVShows LibraryKind = "tvshows"
-)
-
-func (k LibraryKind) String() string {
-	return string(k)
-}
-
-type Library struct {
-	ID   int
-	Name string
-}
-
-type librarySections struct {
-	MediaContainer struct {
-		Directory []struct {
-			Key   string `json:"key"`
-			Title string `json:"title"`
-		} `json:"Directory"`
-	} `json:"MediaContainer"`
-}
-
-var (
-	cachedLibraries []Library
-)
+// Returns the first line of given response body, truncated to keep error messages readable.
+func summarizeBody(body []byte) string {
+	const maxLength = 200
 
-func (p *Service) Libraries() ([]Library, error) {
-	if cachedLibraries == nil {
-		var sections librarySections
-		if err := p.Get("/library/sections", &sections); err != nil {
-			return nil, fmt.Errorf("failed to get library sections: %w", err)
-		}
-
-		cachedLibraries = make([]Library, len(sections.MediaContainer.Directory))
-		for _, d := range sections.MediaContainer.Directory {
-			id, err := strconv.Atoi(d.Key)
-			if err != nil {
-				return nil, fmt.Errorf("could not convert library ID to integer: %w", err)
-			}
-
-			cachedLibraries = append(cachedLibraries, Library{
-				ID:   id,
-				Name: d.Title,
-			})
-		}
-	}
-
-	return cachedLibraries, nil
-}
-
-func (p *Service) LibraryID(kind LibraryKind) (int, error) {
-	libraries, err := p.Libraries()
-	if err != nil {
-		return 0, fmt.Errorf("failed to list libraries: %w", err)
-	}
-
-	for _, lib := range libraries {
-		if strings.ToLower(strings.ReplaceAll(lib.Name, " ", "")) == kind.String() {
-			return lib.ID, nil
-		}
+	summary, _, _ := strings.Cut(strings.TrimSpace(string(body)), "\n")
+	if len(summary) > maxLength {
+		summary = summary[:maxLength] + "..."
 	}
-
-	return 0, fmt.Errorf("could not find library kind %s", kind)
+	return summary
 }
@@ -0,0 +1,223 @@
+package plex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+)
+
+func newTestService(t *testing.T, handler http.HandlerFunc, opts ...Option) *Service {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewService(server.URL, "token", opts...)
+}
+
+func Test_Get_Decodes_JSON_Response(t *testing.T) {
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/library/metadata/42", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("X-Plex-Token"))
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		w.Write([]byte(`{"MediaContainer":{"size":1}}`))
+	})
+
+	var output struct {
+		MediaContainer struct {
+			Size int `json:"size"`
+		} `json:"MediaContainer"`
+	}
+	err := service.Get(context.Background(), "/library/metadata/42", &output)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, output.MediaContainer.Size)
+}
+
+func Test_Get_Returns_Typed_Errors(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		expected   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusServiceUnavailable, ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte("<html><head><title>Unauthorized</title></head></html>"))
+			})
+
+			err := service.Get(context.Background(), "/library/sections", &struct{}{})
+
+			assert.ErrorIs(t, err, tt.expected)
+			var statusErr *StatusError
+			require.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, tt.statusCode, statusErr.StatusCode)
+			assert.Equal(t, "/library/sections", statusErr.Path)
+		})
+	}
+}
+
+func Test_Do_Sends_Method_Query_And_Body(t *testing.T) {
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/library/metadata/42", r.URL.Path)
+		assert.Equal(t, "value", r.URL.Query().Get("key"))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	err := service.Put(context.Background(), "/library/metadata/42", map[string][]string{"key": {"value"}}, nil)
+	assert.NoError(t, err)
+}
+
+func Test_Delete(t *testing.T) {
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	assert.NoError(t, service.Delete(context.Background(), "/library/metadata/42"))
+}
+
+func Test_Timeout(t *testing.T) {
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}, WithTimeout(50*time.Millisecond))
+
+	err := service.Get(context.Background(), "/", &struct{}{})
+	assert.Error(t, err)
+}
+
+func Test_Configured_Timeout(t *testing.T) {
+	for value, expected := range map[any]time.Duration{
+		10:        10 * time.Second,
+		"10":      10 * time.Second,
+		"1m30s":   90 * time.Second,
+		"invalid": DefaultTimeout,
+	} {
+		configtest.Set(t, map[string]any{config.KeyPlexAPITimeout: value})
+		assert.Equal(t, expected, configuredTimeout(), "timeout set to %v", value)
+	}
+}
+
+func Test_Context_Cancellation(t *testing.T) {
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := service.Get(ctx, "/", &struct{}{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_Insecure_Skip_Verify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	err := NewService(server.URL, "token").Get(context.Background(), "/", &struct{}{})
+	assert.Error(t, err)
+
+	err = NewService(server.URL, "token", WithInsecureSkipVerify(true)).Get(context.Background(), "/", &struct{}{})
+	assert.NoError(t, err)
+}
+
+func Test_Libraries(t *testing.T) {
+	calls := 0
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"MediaContainer":{"Directory":[
+			{"key":"1","title":"Movies","type":"movie","Location":[{"path":"/data/movies"}]},
+			{"key":"2","title":"TV Shows","type":"show","Location":[{"path":"/data/tvshows"},{"path":"/data2/tvshows"}]}
+		]}}`))
+	})
+
+	libraries, err := service.Libraries(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Library{
+		{ID: 1, Name: "Movies", Type: "movie", Locations: []string{"/data/movies"}},
+		{ID: 2, Name: "TV Shows", Type: "show", Locations: []string{"/data/tvshows", "/data2/tvshows"}},
+	}, libraries)
+
+	id, err := service.LibraryID(context.Background(), LibraryKindTVShows)
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.Equal(t, 1, calls)
+
+	_, err = service.LibraryID(context.Background(), LibraryKindAnimes)
+	assert.ErrorContains(t, err, "could not find library kind animes")
+}
+
+func Test_Refresh(t *testing.T) {
+	var paths []string
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/library/sections/2/refresh", r.URL.Path)
+		paths = append(paths, r.URL.Query().Get("path"))
+	})
+
+	err := service.Refresh(context.Background(), 2, "/data/tvshows/Show A", "/data/tvshows/Show & B")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/data/tvshows/Show A", "/data/tvshows/Show & B"}, paths)
+}
+
+func Test_LibraryID_With_Configured_Sections(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"MediaContainer":{"Directory":[
+			{"key":"3","title":"Films","type":"movie"},
+			{"key":"4","title":"Séries","type":"show"}
+		]}}`))
+	}
+	service := newTestService(t, handler, WithSections(map[LibraryKind]string{
+		LibraryKindMovies:  "3",
+		LibraryKindTVShows: "séries",
+		LibraryKindAnimes:  "Animés",
+	}))
+
+	id, err := service.LibraryID(context.Background(), LibraryKindMovies)
+	require.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	id, err = service.LibraryID(context.Background(), LibraryKindTVShows)
+	require.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	_, err = service.LibraryID(context.Background(), LibraryKindAnimes)
+	assert.ErrorContains(t, err, `could not find Plex section "Animés" configured for animes`)
+}
+
+func Test_Upload_Poster_And_Art(t *testing.T) {
+	var requests []string
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "image/jpeg", r.Header.Get("Content-Type"))
+		requests = append(requests, r.URL.Path+" "+string(body))
+	})
+
+	require.NoError(t, service.UploadPoster(context.Background(), "42", strings.NewReader("poster")))
+	require.NoError(t, service.UploadArt(context.Background(), "42", strings.NewReader("background")))
+
+	assert.Equal(t, []string{
+		"/library/metadata/42/posters poster",
+		"/library/metadata/42/arts background",
+	}, requests)
+}
@@ -0,0 +1,249 @@
+package plex
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Keys documented for .plexmatch files.
+const (
+	MatchKeyEpisode = "episode"
+	MatchKeyGUID    = "guid"
+	MatchKeyIMDbID  = "imdbid"
+	MatchKeySeason  = "season"
+	MatchKeyShow    = "show"
+	MatchKeyTitle   = "title"
+	MatchKeyTMDbID  = "tmdbid"
+	MatchKeyTVDbID  = "tvdbid"
+	MatchKeyYear    = "year"
+
+	// Short form of MatchKeyEpisode.
+	MatchKeyEp = "ep"
+)
+
+var (
+	matchLineRegexp = regexp.MustCompile(`^\s*(?<key>[A-Za-z]+)\s*[:=]\s*(?<value>.*?)\s*$`)
+)
+
+// Content of a .plexmatch file. Lines are kept in order so that comments and unknown keys survive a round-trip.
+type MatchFile struct {
+	Entries []*MatchEntry
+}
+
+// Single line of a .plexmatch file. Comments, blank lines and unrecognized lines have no key.
+type MatchEntry struct {
+	Key     string
+	Value   string
+	Comment string
+
+	// Original line, written back as is when the entry was not modified.
+	raw string
+}
+
+// Per-episode override set with "ep:" or "episode:" entries, e.g. "ep: S01E01: Pilot.mkv".
+type MatchEpisode struct {
+	Episode string
+	Path    string
+}
+
+// Identifier of a metadata agent database, e.g. "imdb" for "imdbid" entries.
+type MatchID struct {
+	Identifier string
+	Value      string
+}
+
+// Parses .plexmatch content from given reader.
+func ParseMatchFile(r io.Reader) (*MatchFile, error) {
+	file := &MatchFile{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			file.Entries = append(file.Entries, &MatchEntry{
+				Comment: strings.TrimSpace(strings.TrimPrefix(trimmed, "#")),
+				raw:     line,
+			})
+			continue
+		}
+
+		// Lines Plex would not understand either are kept as is rather than failing on a hand-edited file.
+		matches := matchLineRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			file.Entries = append(file.Entries, &MatchEntry{raw: line})
+			continue
+		}
+		file.Entries = append(file.Entries, &MatchEntry{
+			Key:   strings.ToLower(matches[1]),
+			Value: matches[2],
+			raw:   line,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read .plexmatch content: %w", err)
+	}
+
+	return file, nil
+}
+
+// Returns the value of the first entry with given key, or an empty string if there is none.
+func (f *MatchFile) Get(key string) string {
+	key = strings.ToLower(key)
+	for _, entry := range f.Entries {
+		if entry.Key == key {
+			return entry.Value
+		}
+	}
+	return ""
+}
+
+// Sets the value of the first entry with given key, appending a new entry if there is none.
+func (f *MatchFile) Set(key, value string) {
+	key = strings.ToLower(key)
+	for _, entry := range f.Entries {
+		if entry.Key == key {
+			if entry.Value != value {
+				entry.Value = value
+				entry.raw = ""
+			}
+			return
+		}
+	}
+	f.Entries = append(f.Entries, &MatchEntry{Key: key, Value: value})
+}
+
+// Removes all entries with given key.
+func (f *MatchFile) Delete(key string) {
+	key = strings.ToLower(key)
+	f.Entries = slices.DeleteFunc(f.Entries, func(entry *MatchEntry) bool {
+		return entry.Key == key
+	})
+}
+
+// Returns the title of the item, falling back to the "show" alias.
+func (f *MatchFile) Title() string {
+	return cmp.Or(f.Get(MatchKeyTitle), f.Get(MatchKeyShow))
+}
+
+// Returns the year of the item, or 0 if it is missing or invalid.
+func (f *MatchFile) Year() int {
+	year, _ := strconv.Atoi(f.Get(MatchKeyYear))
+	return year
+}
+
+// Returns the season number hint, used in season folders, and whether it is set.
+func (f *MatchFile) Season() (int, bool) {
+	season, err := strconv.Atoi(f.Get(MatchKeySeason))
+	if err != nil {
+		return 0, false
+	}
+	return season, true
+}
+
+// Returns database IDs found in "<agent>id" entries, sorted by identifier.
+func (f *MatchFile) IDs() []*MatchID {
+	var ids []*MatchID
+	for _, entry := range f.Entries {
+		if identifier, ok := matchIDIdentifier(entry.Key); ok {
+			ids = append(ids, &MatchID{Identifier: identifier, Value: entry.Value})
+		}
+	}
+	slices.SortFunc(ids, func(a, b *MatchID) int {
+		return cmp.Compare(a.Identifier, b.Identifier)
+	})
+	return ids
+}
+
+// Replaces database IDs with given ones. Existing entries are updated in place, stale ones are removed and new ones are
+// appended after the last ID entry so that any other content is kept.
+func (f *MatchFile) SetIDs(ids []*MatchID) {
+	values := make(map[string]string, len(ids))
+	for _, id := range ids {
+		values[strings.ToLower(id.Identifier)+"id"] = id.Value
+	}
+
+	insertAt := len(f.Entries)
+	entries := make([]*MatchEntry, 0, len(f.Entries)+len(ids))
+	for _, entry := range f.Entries {
+		if _, ok := matchIDIdentifier(entry.Key); ok {
+			value, found := values[entry.Key]
+			if !found {
+				continue
+			}
+			if entry.Value != value {
+				entry.Value = value
+				entry.raw = ""
+			}
+			delete(values, entry.Key)
+			insertAt = len(entries) + 1
+		}
+		entries = append(entries, entry)
+	}
+	insertAt = min(insertAt, len(entries))
+
+	var added []*MatchEntry
+	for _, id := range ids {
+		key := strings.ToLower(id.Identifier) + "id"
+		if value, ok := values[key]; ok {
+			added = append(added, &MatchEntry{Key: key, Value: value})
+			delete(values, key)
+		}
+	}
+
+	f.Entries = slices.Insert(entries, insertAt, added...)
+}
+
+// Returns per-episode overrides in file order.
+func (f *MatchFile) Episodes() []*MatchEpisode {
+	var episodes []*MatchEpisode
+	for _, entry := range f.Entries {
+		if entry.Key != MatchKeyEp && entry.Key != MatchKeyEpisode {
+			continue
+		}
+		episode, path, _ := strings.Cut(entry.Value, ":")
+		episodes = append(episodes, &MatchEpisode{
+			Episode: strings.TrimSpace(episode),
+			Path:    strings.TrimSpace(path),
+		})
+	}
+	return episodes
+}
+
+// Returns the file content, ending with a newline unless empty.
+func (f *MatchFile) String() string {
+	var content strings.Builder
+	for _, entry := range f.Entries {
+		content.WriteString(entry.String())
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+func (e *MatchEntry) String() string {
+	switch {
+	case e.raw != "":
+		return e.raw
+	case e.Key == "" && e.Comment == "":
+		return ""
+	case e.Key == "":
+		return "# " + e.Comment
+	default:
+		return e.Key + ": " + e.Value
+	}
+}
+
+// Returns the agent identifier of given "<agent>id" key, e.g. "tvdb" for "tvdbid".
+func matchIDIdentifier(key string) (string, bool) {
+	if key == MatchKeyGUID || !strings.HasSuffix(key, "id") || len(key) == len("id") {
+		return "", false
+	}
+	return strings.TrimSuffix(key, "id"), true
+}
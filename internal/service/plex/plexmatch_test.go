@@ -0,0 +1,71 @@
+package plex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMatchFileContent = `# Hand-written hints
+Title: Bluey
+year=2018
+tvdbid: 353546
+guid: plex://show/5d9c08254eefaa001f5daa50
+
+season: 1
+ep: S01E01: Magic Xylophone.mkv
+Episode: 02: Hospital.mkv
+`
+
+func Test_ParseMatchFile_Round_Trips(t *testing.T) {
+	file, err := ParseMatchFile(strings.NewReader(testMatchFileContent))
+
+	require.NoError(t, err)
+	assert.Equal(t, testMatchFileContent, file.String())
+	assert.Equal(t, "Bluey", file.Title())
+	assert.Equal(t, 2018, file.Year())
+	assert.Equal(t, "plex://show/5d9c08254eefaa001f5daa50", file.Get(MatchKeyGUID))
+	season, ok := file.Season()
+	assert.True(t, ok)
+	assert.Equal(t, 1, season)
+	assert.Equal(t, []*MatchID{{Identifier: "tvdb", Value: "353546"}}, file.IDs())
+	assert.Equal(t, []*MatchEpisode{
+		{Episode: "S01E01", Path: "Magic Xylophone.mkv"},
+		{Episode: "02", Path: "Hospital.mkv"},
+	}, file.Episodes())
+}
+
+func Test_ParseMatchFile_Keeps_Unknown_Lines(t *testing.T) {
+	file, err := ParseMatchFile(strings.NewReader("title: Bluey\nnot a hint\ntvdbid: 1\n"))
+	require.NoError(t, err)
+
+	file.SetIDs([]*MatchID{{Identifier: "tvdb", Value: "353546"}})
+
+	assert.Equal(t, "Bluey", file.Title())
+	assert.Equal(t, "title: Bluey\nnot a hint\ntvdbid: 353546\n", file.String())
+}
+
+func Test_MatchFile_SetIDs_Keeps_Other_Entries(t *testing.T) {
+	file, err := ParseMatchFile(strings.NewReader("# Bluey\ntitle: Bluey\nimdbid: tt0000000\ntvdbid: 353546\nep: S01E01: Pilot.mkv\n"))
+	require.NoError(t, err)
+
+	file.SetIDs([]*MatchID{
+		{Identifier: "imdb", Value: "tt7678620"},
+		{Identifier: "tmdb", Value: "82728"},
+	})
+
+	assert.Equal(t, "# Bluey\ntitle: Bluey\nimdbid: tt7678620\ntmdbid: 82728\nep: S01E01: Pilot.mkv\n", file.String())
+}
+
+func Test_MatchFile_SetIDs_On_Empty_File(t *testing.T) {
+	file := &MatchFile{}
+
+	file.SetIDs([]*MatchID{
+		{Identifier: "imdb", Value: "tt7678620"},
+		{Identifier: "tvdb", Value: "353546"},
+	})
+
+	assert.Equal(t, "imdbid: tt7678620\ntvdbid: 353546\n", file.String())
+}
@@ -5,12 +5,26 @@ import (
 	"github.com/jeremiergz/nas-cli/internal/service/internal/ssh"
 )
 
+type (
+	// Host key presented by an SSH server, see SSH.ScanHostKeys.
+	HostKey = ssh.HostKey
+)
+
+const (
+	HostKeyChanged = ssh.HostKeyChanged
+	HostKeyKnown   = ssh.HostKeyKnown
+	HostKeyUnknown = ssh.HostKeyUnknown
+)
+
 var (
+	// SFTP session opened over the SSH connection.
 	SFTP *sftp.Service
-	SSH  *ssh.Service
+
+	// SSH connection shared by every service, dialed on first use.
+	SSH *ssh.Service
 )
 
 func init() {
-	SFTP = sftp.New()
 	SSH = ssh.New()
+	SFTP = sftp.New(SSH)
 }
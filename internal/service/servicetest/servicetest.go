@@ -0,0 +1,373 @@
+// Package servicetest provides an in-process SSH server for testing code relying on the shared SSH and SFTP services.
+package servicetest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/jeremiergz/nas-cli/internal/config"
+	"github.com/jeremiergz/nas-cli/internal/config/configtest"
+	svc "github.com/jeremiergz/nas-cli/internal/service"
+	"github.com/jeremiergz/nas-cli/internal/util/cmdutil"
+)
+
+// Handles a command sent through an exec request, writing its output to given streams and returning its exit status.
+type CommandHandler func(cmd string, stdout, stderr io.Writer) int
+
+// SSH server serving a temporary directory over SFTP, as if it were the root of the remote file system. Commands are
+// answered by registered handlers, matched by prefix.
+type Server struct {
+	// Local directory holding the remote file system.
+	Root string
+
+	// Address the server listens on.
+	Addr string
+
+	// Key the server presents, trusted by the configured known hosts file.
+	HostKey ssh.PublicKey
+
+	mu       sync.Mutex
+	handlers map[string]CommandHandler
+	commands []string
+	requests []string
+	conns    []*ssh.ServerConn
+}
+
+// Starts a server and points the SSH configuration at it for the duration of the test. Files are owned by the current
+// user, so the configured owner is set to the current user as well. Concurrency is enabled as the root command would,
+// so that code running goroutines against the server does not block.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		Root:     t.TempDir(),
+		handlers: map[string]CommandHandler{},
+	}
+	s.HandleCommand("mv -n -- ", s.mv)
+	s.HandleCommand("sha256sum ", s.sha256sum)
+
+	hostKey := newSigner(t, newKey(t))
+	s.HostKey = hostKey.PublicKey()
+	clientPrivateKey := newKey(t)
+	clientKey := newSigner(t, clientPrivateKey)
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.PublicKey().Marshal()) {
+				return nil, fmt.Errorf("unknown public key")
+			}
+			return nil, nil
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	s.Addr = listener.Addr().String()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, serverConfig)
+		}
+	}()
+
+	s.configure(t, hostKey, clientPrivateKey)
+
+	previousMaxConcurrentGoroutines := cmdutil.MaxConcurrentGoroutines
+	cmdutil.MaxConcurrentGoroutines = 10
+	t.Cleanup(func() { cmdutil.MaxConcurrentGoroutines = previousMaxConcurrentGoroutines })
+
+	t.Cleanup(func() {
+		svc.SFTP.Disconnect()
+		svc.SSH.Disconnect()
+		listener.Close()
+	})
+
+	return s
+}
+
+// Registers given handler for commands starting with given prefix. The longest matching prefix wins.
+func (s *Server) HandleCommand(prefix string, handler CommandHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[prefix] = handler
+}
+
+// Returns the commands received so far, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+// Returns the types of the session requests received so far, in order.
+func (s *Server) RequestTypes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// Returns the number of connections accepted so far.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// Closes every connection from the server side, as if the network dropped them.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Returns the local path of given remote path.
+func (s *Server) LocalPath(remotePath string) string {
+	return filepath.Join(s.Root, filepath.Clean("/"+remotePath))
+}
+
+// Creates given remote file along with its parent directories.
+func (s *Server) WriteFile(t testing.TB, remotePath string, content string) {
+	t.Helper()
+
+	localPath := s.LocalPath(remotePath)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		t.Fatalf("unable to create %s parent directory: %v", remotePath, err)
+	}
+	if err := os.WriteFile(localPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("unable to write %s: %v", remotePath, err)
+	}
+}
+
+// Creates given remote directories along with their parents.
+func (s *Server) MkdirAll(t testing.TB, remotePaths ...string) {
+	t.Helper()
+
+	for _, remotePath := range remotePaths {
+		if err := os.MkdirAll(s.LocalPath(remotePath), 0o755); err != nil {
+			t.Fatalf("unable to create %s: %v", remotePath, err)
+		}
+	}
+}
+
+// Returns the content of given remote file.
+func (s *Server) ReadFile(t testing.TB, remotePath string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(s.LocalPath(remotePath))
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", remotePath, err)
+	}
+	return string(content)
+}
+
+// Writes the keys and known hosts files, then sets the configuration entries, restoring them once the test is done.
+func (s *Server) configure(t testing.TB, hostKey ssh.Signer, clientKey ed25519.PrivateKey) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.Addr)}, hostKey.PublicKey())
+	if err := os.WriteFile(knownHostsPath, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatalf("unable to write known hosts file: %v", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatalf("unable to encode private key: %v", err)
+	}
+	privateKeyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(privateKeyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("unable to write private key: %v", err)
+	}
+
+	host, port, _ := net.SplitHostPort(s.Addr)
+	configtest.Set(t, map[string]any{
+		config.KeySCPChownGID:         os.Getgid(),
+		config.KeySCPChownUID:         os.Getuid(),
+		config.KeySSHClientAgent:      false,
+		config.KeySSHClientConfig:     "",
+		config.KeySSHClientKnownHosts: knownHostsPath,
+		config.KeySSHClientPrivateKey: privateKeyPath,
+		config.KeySSHHost:             host,
+		config.KeySSHJump:             "",
+		config.KeySSHPort:             port,
+		config.KeySSHUser:             "test",
+	})
+}
+
+func (s *Server) serve(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	serverConn, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer serverConn.Close()
+
+	s.mu.Lock()
+	s.conns = append(s.conns, serverConn)
+	s.mu.Unlock()
+
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveSession(channel, requests)
+	}
+}
+
+func (s *Server) serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		s.mu.Lock()
+		s.requests = append(s.requests, req.Type)
+		s.mu.Unlock()
+
+		var payload struct{ Value string }
+		switch req.Type {
+		case "exec":
+			ssh.Unmarshal(req.Payload, &payload)
+			req.Reply(true, nil)
+			status := s.run(payload.Value, channel, channel.Stderr())
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+			return
+
+		case "subsystem":
+			ssh.Unmarshal(req.Payload, &payload)
+			if payload.Value != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			server := sftp.NewRequestServer(channel, newRootedHandlers(s.Root))
+			server.Serve()
+			server.Close()
+			return
+
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *Server) run(cmd string, stdout, stderr io.Writer) int {
+	s.mu.Lock()
+	s.commands = append(s.commands, cmd)
+	var handler CommandHandler
+	var matched string
+	for prefix, h := range s.handlers {
+		if strings.HasPrefix(cmd, prefix) && len(prefix) >= len(matched) {
+			handler, matched = h, prefix
+		}
+	}
+	s.mu.Unlock()
+
+	if handler == nil {
+		name, _, _ := strings.Cut(cmd, " ")
+		fmt.Fprintf(stderr, "%s: command not found\n", name)
+		return 127
+	}
+	return handler(cmd, stdout, stderr)
+}
+
+var mvRegexp = regexp.MustCompile(`^mv -n -- ('(?:[^']|'\\'')*') ('(?:[^']|'\\'')*')$`)
+
+// Moves a remote file or directory, as remote moves across pools expect it.
+func (s *Server) mv(cmd string, stdout, stderr io.Writer) int {
+	matches := mvRegexp.FindStringSubmatch(cmd)
+	if matches == nil {
+		fmt.Fprintf(stderr, "mv: invalid arguments\n")
+		return 1
+	}
+	source, target := unquote(matches[1]), unquote(matches[2])
+
+	if _, err := os.Stat(s.LocalPath(target)); err == nil {
+		return 0
+	}
+	if err := os.Rename(s.LocalPath(source), s.LocalPath(target)); err != nil {
+		fmt.Fprintf(stderr, "mv: cannot move %s to %s: %v\n", source, target, err)
+		return 1
+	}
+	return 0
+}
+
+// Computes the checksum of a remote file, as the upload verification expects it.
+func (s *Server) sha256sum(cmd string, stdout, stderr io.Writer) int {
+	remotePath := unquote(strings.TrimPrefix(cmd, "sha256sum "))
+
+	file, err := os.Open(s.LocalPath(remotePath))
+	if err != nil {
+		fmt.Fprintf(stderr, "sha256sum: %s: No such file or directory\n", remotePath)
+		return 1
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		fmt.Fprintf(stderr, "sha256sum: %s: %v\n", remotePath, err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s  %s\n", hex.EncodeToString(hash.Sum(nil)), remotePath)
+	return 0
+}
+
+// Reverts util.ShellQuote.
+func unquote(s string) string {
+	if len(s) < 2 || !strings.HasPrefix(s, "'") || !strings.HasSuffix(s, "'") {
+		return s
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], `'\''`, "'")
+}
+
+func newKey(t testing.TB) ed25519.PrivateKey {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	return privateKey
+}
+
+func newSigner(t testing.TB, privateKey ed25519.PrivateKey) ssh.Signer {
+	t.Helper()
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatalf("unable to create signer: %v", err)
+	}
+	return signer
+}
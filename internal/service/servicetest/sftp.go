@@ -0,0 +1,141 @@
+package servicetest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+)
+
+// SFTP handlers mapping remote paths to a local directory.
+type rootedHandlers struct {
+	root string
+}
+
+func newRootedHandlers(root string) sftp.Handlers {
+	h := &rootedHandlers{root: root}
+	return sftp.Handlers{
+		FileGet:  h,
+		FilePut:  h,
+		FileCmd:  h,
+		FileList: h,
+	}
+}
+
+func (h *rootedHandlers) localPath(remotePath string) string {
+	return filepath.Join(h.root, filepath.Clean("/"+remotePath))
+}
+
+func (h *rootedHandlers) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	return os.Open(h.localPath(r.Filepath))
+}
+
+func (h *rootedHandlers) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	pflags := r.Pflags()
+	flags := os.O_WRONLY
+	if pflags.Read {
+		flags = os.O_RDWR
+	}
+	if pflags.Creat {
+		flags |= os.O_CREATE
+	}
+	if pflags.Trunc {
+		flags |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flags |= os.O_EXCL
+	}
+	return os.OpenFile(h.localPath(r.Filepath), flags, 0o644)
+}
+
+func (h *rootedHandlers) Filecmd(r *sftp.Request) error {
+	localPath := h.localPath(r.Filepath)
+
+	switch r.Method {
+	case "Setstat":
+		attrs := r.Attributes()
+		attrFlags := r.AttrFlags()
+		if attrFlags.Size {
+			if err := os.Truncate(localPath, int64(attrs.Size)); err != nil {
+				return err
+			}
+		}
+		if attrFlags.Permissions {
+			if err := os.Chmod(localPath, attrs.FileMode().Perm()); err != nil {
+				return err
+			}
+		}
+		if attrFlags.UidGid {
+			if err := os.Chown(localPath, int(attrs.UID), int(attrs.GID)); err != nil {
+				return err
+			}
+		}
+		if attrFlags.Acmodtime {
+			if err := os.Chtimes(localPath, attrs.AccessTime(), attrs.ModTime()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "Rename", "PosixRename":
+		return os.Rename(localPath, h.localPath(r.Target))
+
+	case "Rmdir", "Remove":
+		return os.Remove(localPath)
+
+	case "Mkdir":
+		return os.Mkdir(localPath, 0o755)
+
+	case "Link":
+		return os.Link(h.localPath(r.Target), localPath)
+
+	case "Symlink":
+		return os.Symlink(r.Target, localPath)
+	}
+
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+func (h *rootedHandlers) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	localPath := h.localPath(r.Filepath)
+
+	switch r.Method {
+	case "List":
+		entries, err := os.ReadDir(localPath)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]os.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+		return listerAt(infos), nil
+
+	case "Stat":
+		info, err := os.Stat(localPath)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{info}, nil
+	}
+
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(infos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -1,6 +1,8 @@
 package cmdutil
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
@@ -13,6 +15,7 @@ import (
 	"github.com/jedib0t/go-pretty/v6/progress"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -53,6 +56,15 @@ func AddOutputFlag(cmd *cobra.Command) {
 	})
 }
 
+// Adds --output to given command and its subcommands, without shorthand for command trees where "-o" is already
+// taken.
+func AddPersistentOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&OutputFormat, "output", "text", "select output format")
+	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return OutputFormats, cobra.ShellCompDirectiveDefault
+	})
+}
+
 // Runs ParentPersistentPreRun if defined.
 func CallParentPersistentPreRun(cmd *cobra.Command, args []string) {
 	if parent := cmd.Parent(); parent != nil {
@@ -119,6 +131,32 @@ func OnlyValidOutputs() error {
 	return nil
 }
 
+// Prints given value to given writer in the selected --output format, either JSON or YAML.
+func PrintStructured(out io.Writer, v any) error {
+	var toPrint string
+	switch OutputFormat {
+	case "json":
+		out, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("could not encode output: %w", err)
+		}
+		toPrint = strings.TrimSpace(string(out))
+
+	case "yaml":
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("could not encode output: %w", err)
+		}
+		toPrint = strings.TrimSpace(buf.String())
+	}
+
+	fmt.Fprintln(out, toPrint)
+
+	return nil
+}
+
 var mkvMergeProgressRegexp = regexp.MustCompile(`(?m)(?:progress\s+)(?P<Percentage>\d+)(?:%)`)
 
 func GetMKVMergeProgress(str string) (percentage int, err error) {
@@ -3,7 +3,9 @@ package util
 import (
 	"fmt"
 	"os/user"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -28,6 +30,12 @@ var (
 	ownershipRegexp = regexp.MustCompile(`^(\w+):?(\w+)?$`)
 )
 
+// Returns whether given file name has one of the accepted video extensions, regardless of case.
+func IsVideo(name string) bool {
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	return slices.Contains(AcceptedVideoExtensions, extension)
+}
+
 func InitOwnership(ownership string) (err error) {
 	selectedUser, _ := user.Current()
 	selectedGroup := &user.Group{Gid: selectedUser.Gid}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"unicode"
 
@@ -34,6 +35,27 @@ func RemoveDiacritics(s string) string {
 	return output
 }
 
+// Formats given size in bytes into a human readable string using binary units (e.g. "1.5 GiB").
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// Quotes given string so that it is interpreted as a single argument by a POSIX shell.
+//
+// Useful when building commands that are executed on the remote server.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // Returns an error from a list of strings.
 //
 // Useful when using stdout & stderr buffers from a command output.